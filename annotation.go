@@ -0,0 +1,69 @@
+package schemalex
+
+import (
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// annotationRenamedFrom is the annotation that describes the previous name of a table or a column.
+//
+//	CREATE TABLE hoge (
+//	    -- schemalex:renamed-from old_name
+//	    new_name INTEGER NOT NULL
+//	);
+const annotationRenamedFrom = "schemalex:renamed-from"
+
+// annotationFile is the annotation that ReadFileSet inserts at the start of each file.
+// The current database selected by USE and the delimiter changed by DELIMITER are reset there,
+// so they don't continue to the next file.
+//
+//	-- schemalex:file schema/a.sql
+const annotationFile = "schemalex:file"
+
+// parseRenamedFrom parses a comment, and returns the name in the renamed-from annotation.
+func parseRenamedFrom(comment string) (model.Ident, bool) {
+	s, ok := parseAnnotation(comment, annotationRenamedFrom)
+	if !ok {
+		return "", false
+	}
+
+	// the name may be quoted by backquotes.
+	if len(s) >= 2 && s[0] == '`' && s[len(s)-1] == '`' {
+		s = unescapeQuotes(s, '`')
+	}
+	if s == "" {
+		return "", false
+	}
+	return model.Ident(s), true
+}
+
+// isFileAnnotation returns whether the comment is the file annotation.
+func isFileAnnotation(comment string) bool {
+	_, ok := parseAnnotation(comment, annotationFile)
+	return ok
+}
+
+// parseAnnotation parses a comment, and returns the argument of the annotation.
+func parseAnnotation(comment, annotation string) (string, bool) {
+	s := strings.TrimSpace(comment)
+	switch {
+	case strings.HasPrefix(s, "--"):
+		s = strings.TrimPrefix(s, "--")
+	case strings.HasPrefix(s, "#"):
+		s = strings.TrimPrefix(s, "#")
+	case strings.HasPrefix(s, "/*"):
+		s = strings.TrimPrefix(s, "/*")
+		s = strings.TrimSuffix(s, "*/")
+	}
+	s = strings.TrimSpace(s)
+
+	if !strings.HasPrefix(s, annotation) {
+		return "", false
+	}
+	s = strings.TrimPrefix(s, annotation)
+	if s == "" || !isSpace(rune(s[0])) {
+		return "", false
+	}
+	return strings.TrimSpace(s), true
+}
@@ -9,6 +9,9 @@ import (
 	"runtime"
 	"strconv"
 
+	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/deploy"
+	"github.com/shogo82148/schemalex-deploy/model"
 	"github.com/shogo82148/schemalex-deploy/mycnf"
 )
 
@@ -20,6 +23,10 @@ const (
 	ExecModeDeploy ExecMode = "deploy"
 	// ExecModeImport import mode
 	ExecModeImport ExecMode = "import"
+	// ExecModeRollback rollback mode
+	ExecModeRollback ExecMode = "rollback"
+	// ExecModeCheckDrift check-drift mode
+	ExecModeCheckDrift ExecMode = "check-drift"
 )
 
 type config struct {
@@ -30,10 +37,17 @@ type config struct {
 	password    string
 	database    string
 	port        int
-	schema      []byte
+	schema      *schemalex.FileSet
 	autoApprove bool
 	dryRun      bool
 	mode        ExecMode
+
+	allowDestructive bool
+	output           string
+	source           deploy.Source
+	strategy         deploy.Strategy
+	chunkSize        int
+	serverVersion    *model.ServerVersion
 }
 
 func loadConfig() (*config, error) {
@@ -45,10 +59,21 @@ func loadConfig() (*config, error) {
 	var approve bool
 	var dryRun bool
 	var runImport bool
+	var runRollback bool
+	var checkDrift bool
+	var allowDestructive bool
+	var output string
+	var source string
+	var strategy string
+	var chunkSize int
+	var serverVersion string
 
 	flag.Usage = func() {
 		fmt.Printf(`schemalex-deploy version %s
 
+usage: schemalex-deploy [options] schema.sql [more.sql | directory | 'glob/*.sql' ...]
+       schemalex-deploy diff [options] old.sql new.sql
+
 -socket           the unix domain socket path for the database
 -host             the host name of the database
 -port             the port number(default: 3306)
@@ -59,6 +84,14 @@ func loadConfig() (*config, error) {
 -auto-approve     skips interactive approval of plan before deploying
 -dry-run          outputs the schema difference, and then exit the program
 -import           imports existing table schemas from running database
+-rollback         deploys the previous revision of the schema
+-check-drift      checks whether the live schema diverges from the latest revision
+-allow-destructive allows deploying the statements that may lose data
+-from             the schema to plan from: revision or live (default: revision)
+-output           the format of the plan: text or json (default: text)
+-strategy         how to execute ALTER TABLE statements: direct, online or copy (default: direct)
+-chunk-size       the number of rows copied at once by the copy strategy (default: 1000)
+-server-version   the version of the server, e.g. 5.7, 8.0.36 or 10.11.6-MariaDB (default: detected)
 `, getVersion())
 	}
 
@@ -76,6 +109,14 @@ func loadConfig() (*config, error) {
 	flag.BoolVar(&approve, "auto-approve", false, "skips interactive approval of plan before deploying")
 	flag.BoolVar(&dryRun, "dry-run", false, "outputs the schema difference, and then exit the program")
 	flag.BoolVar(&runImport, "import", false, "imports existing table schemas from running database")
+	flag.BoolVar(&runRollback, "rollback", false, "deploys the previous revision of the schema")
+	flag.BoolVar(&checkDrift, "check-drift", false, "checks whether the live schema diverges from the latest revision")
+	flag.BoolVar(&allowDestructive, "allow-destructive", false, "allows deploying the statements that may lose data")
+	flag.StringVar(&source, "from", "revision", "the schema to plan from: revision or live")
+	flag.StringVar(&output, "output", "text", "the format of the plan: text or json")
+	flag.StringVar(&strategy, "strategy", "direct", "how to execute ALTER TABLE statements: direct, online or copy")
+	flag.IntVar(&chunkSize, "chunk-size", 1000, "the number of rows copied at once by the copy strategy")
+	flag.StringVar(&serverVersion, "server-version", "", "the version of the server")
 	flag.Parse()
 
 	if version {
@@ -85,11 +126,49 @@ func loadConfig() (*config, error) {
 
 	cfn.autoApprove = approve
 	cfn.dryRun = dryRun
+	cfn.allowDestructive = allowDestructive
+	cfn.chunkSize = chunkSize
+	switch output {
+	case "text", "json":
+		cfn.output = output
+	default:
+		return nil, fmt.Errorf("unknown output format: %q", output)
+	}
+	s, err := deploy.ParseStrategy(strategy)
+	if err != nil {
+		return nil, err
+	}
+	cfn.strategy = s
+	src, err := deploy.ParseSource(source)
+	if err != nil {
+		return nil, err
+	}
+	cfn.source = src
+	if serverVersion != "" {
+		v, err := model.ParseServerVersion(serverVersion)
+		if err != nil {
+			return nil, err
+		}
+		cfn.serverVersion = &v
+	}
 
 	// choose execute mode
 	cfn.mode = ExecModeDeploy
+	modes := 0
 	if runImport {
 		cfn.mode = ExecModeImport
+		modes++
+	}
+	if runRollback {
+		cfn.mode = ExecModeRollback
+		modes++
+	}
+	if checkDrift {
+		cfn.mode = ExecModeCheckDrift
+		modes++
+	}
+	if modes > 1 {
+		return nil, errors.New("-import, -rollback and -check-drift cannot be used together")
 	}
 
 	// load configure from files
@@ -173,7 +252,7 @@ func loadConfig() (*config, error) {
 			flag.Usage()
 			return nil, errors.New("schema file is required")
 		}
-		schema, err := os.ReadFile(flag.Arg(0))
+		schema, err := schemalex.ReadFileSet(flag.Args()...)
 		if err != nil {
 			return nil, err
 		}
@@ -182,3 +261,11 @@ func loadConfig() (*config, error) {
 
 	return &cfn, nil
 }
+
+// versionOptions returns the options that override the detected server version.
+func (cfn *config) versionOptions() []deploy.Option {
+	if cfn.serverVersion == nil {
+		return nil
+	}
+	return []deploy.Option{deploy.WithServerVersion(*cfn.serverVersion)}
+}
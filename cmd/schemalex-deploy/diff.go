@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/deploy"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// runDiff prints the migration between two schemas without databases.
+func runDiff(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `usage: schemalex-deploy diff [options] old.sql new.sql
+
+old.sql and new.sql are files, directories, glob patterns or git:<ref>:<path>.
+e.g. schemalex-deploy diff git:HEAD~1:schema.sql schema.sql
+
+-output           the format of the plan: text or json (default: text)
+-server-version   the version of the server, e.g. 5.7, 8.0.36 or 10.11.6-MariaDB
+`)
+	}
+	var output, serverVersion string
+	fs.StringVar(&output, "output", "text", "the format of the plan: text or json")
+	fs.StringVar(&serverVersion, "server-version", "", "the version of the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format: %q", output)
+	}
+	var opts []deploy.Option
+	if serverVersion != "" {
+		v, err := model.ParseServerVersion(serverVersion)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, deploy.WithServerVersion(v))
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("two schemas are required")
+	}
+
+	from, err := loadSource(ctx, fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", fs.Arg(0), err)
+	}
+	to, err := loadSource(ctx, fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", fs.Arg(1), err)
+	}
+
+	plan, err := deploy.NewPlan(string(from.Source), string(to.Source), opts...)
+	if err != nil {
+		// report the file name and the line in the file.
+		switch {
+		case errors.Is(err, deploy.ErrFromSchema):
+			return fmt.Errorf("failed to parse %s: %w", fs.Arg(0), from.Locate(err))
+		case errors.Is(err, deploy.ErrToSchema):
+			return fmt.Errorf("failed to parse %s: %w", fs.Arg(1), to.Locate(err))
+		}
+		return fmt.Errorf("failed to plan: %w", err)
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	return plan.Preview(os.Stdout)
+}
+
+// loadSource loads the schema from the file, the directory, the glob pattern,
+// or the git object that is specified by git:<ref>:<path>.
+func loadSource(ctx context.Context, src string) (*schemalex.FileSet, error) {
+	if rest, ok := strings.CutPrefix(src, "git:"); ok {
+		schema, err := loadGitSource(ctx, rest)
+		if err != nil {
+			return nil, err
+		}
+		return &schemalex.FileSet{Source: []byte(schema)}, nil
+	}
+	return schemalex.ReadFileSet(src)
+}
+
+// loadGitSource loads the schema from the git object <ref>:<path>.
+// The path is relative to the root of the repository, or relative to the current directory if it starts with "./".
+func loadGitSource(ctx context.Context, object string) (string, error) {
+	if !strings.Contains(object, ":") {
+		return "", fmt.Errorf("invalid git object %q: want git:<ref>:<path>", object)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "show", object)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git show %s: %s", object, msg)
+		}
+		return "", fmt.Errorf("git show %s: %w", object, err)
+	}
+	return stdout.String(), nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -31,6 +32,13 @@ func main() {
 }
 
 func _main() error {
+	// the diff subcommand works without databases.
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		return runDiff(ctx, os.Args[2:])
+	}
+
 	cfn, err := loadConfig()
 	if err != nil {
 		return err
@@ -62,7 +70,7 @@ func _main() error {
 		"sql_mode": "'TRADITIONAL,NO_AUTO_VALUE_ON_ZERO,ONLY_FULL_GROUP_BY'",
 	}
 
-	db, err := deploy.Open("mysql", config.FormatDSN())
+	db, err := deploy.OpenContext(ctx, "mysql", config.FormatDSN())
 	if err != nil {
 		return err
 	}
@@ -74,6 +82,12 @@ func _main() error {
 
 	case ExecModeImport:
 		return runImport(ctx, db, cfn)
+
+	case ExecModeRollback:
+		return runRollback(ctx, db, cfn)
+
+	case ExecModeCheckDrift:
+		return runCheckDrift(ctx, db, cfn)
 	}
 
 	return nil
@@ -81,21 +95,105 @@ func _main() error {
 
 func runDeploy(ctx context.Context, db *deploy.DB, cfn *config) error {
 	// plan
-	plan, err := db.Plan(ctx, string(cfn.schema))
+	plan, err := db.Plan(ctx, string(cfn.schema.Source), append(cfn.versionOptions(), deploy.WithSource(cfn.source))...)
+	if err != nil {
+		if errors.Is(err, deploy.ErrToSchema) {
+			// report the file name and the line in the file.
+			return fmt.Errorf("failed to parse the schema: %w", cfn.schema.Locate(err))
+		}
+		return fmt.Errorf("failed to plan: %w", err)
+	}
+
+	// preview
+	if err := preview(cfn, plan); err != nil {
+		return fmt.Errorf("failed to preview: %w", err)
+	}
+
+	// dry-run mode: show the rollback plan, and skip deployment
+	if cfn.dryRun {
+		if cfn.output == "text" && len(plan.Rollback) > 0 {
+			if _, err := io.WriteString(os.Stderr, "\n-- rollback plan:\n"); err != nil {
+				return fmt.Errorf("failed to preview: %w", err)
+			}
+			if err := plan.PreviewRollback(os.Stderr); err != nil {
+				return fmt.Errorf("failed to preview: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return applyPlan(ctx, db, cfn, plan)
+}
+
+func runRollback(ctx context.Context, db *deploy.DB, cfn *config) error {
+	// plan
+	plan, err := db.RollbackPlan(ctx, cfn.versionOptions()...)
 	if err != nil {
 		return fmt.Errorf("failed to plan: %w", err)
 	}
 
 	// preview
-	if err := plan.Preview(os.Stderr); err != nil {
+	if err := preview(cfn, plan); err != nil {
 		return fmt.Errorf("failed to preview: %w", err)
 	}
 
+	// report the data that the rollback cannot restore
+	for _, stmt := range plan.Reverse().Unrestorable() {
+		log.Printf("WARNING: the following statement cannot restore the data lost by the previous deploy: %s", stmt.String())
+	}
+
 	// dry-run mode: skip deployment
 	if cfn.dryRun {
 		return nil
 	}
 
+	return applyPlan(ctx, db, cfn, plan)
+}
+
+func runCheckDrift(ctx context.Context, db *deploy.DB, cfn *config) error {
+	drift, err := db.Drift(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check drift: %w", err)
+	}
+
+	if cfn.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(drift); err != nil {
+			return fmt.Errorf("failed to report drift: %w", err)
+		}
+	} else if len(drift.Stmts) > 0 {
+		if _, err := io.WriteString(os.Stderr, "-- the following statements migrate the latest revision to the live schema.\n"); err != nil {
+			return fmt.Errorf("failed to report drift: %w", err)
+		}
+		if err := drift.Preview(os.Stderr); err != nil {
+			return fmt.Errorf("failed to report drift: %w", err)
+		}
+	}
+
+	if len(drift.Stmts) > 0 {
+		return fmt.Errorf("detected drift: the live schema diverges from the revision %d by %d statement(s)", drift.Revision, len(drift.Stmts))
+	}
+	log.Print("no drift detected")
+	return nil
+}
+
+// preview writes the plan in the format specified by -output.
+func preview(cfn *config, plan *deploy.Plan) error {
+	if cfn.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	return plan.Preview(os.Stderr)
+}
+
+func applyPlan(ctx context.Context, db *deploy.DB, cfn *config, plan *deploy.Plan) error {
+	// refuse to lose data unless it is explicitly allowed
+	if stmts := plan.Destructive(); len(stmts) > 0 && !cfn.allowDestructive {
+		return fmt.Errorf("the plan contains %d statement(s) that may lose data. use -allow-destructive to deploy it", len(stmts))
+	}
+
 	// ask to approve
 	if !cfn.autoApprove {
 		if result, err := approved(ctx); err != nil {
@@ -106,7 +204,7 @@ func runDeploy(ctx context.Context, db *deploy.DB, cfn *config) error {
 	}
 
 	// deploy
-	if err := db.Deploy(ctx, plan); err != nil {
+	if err := db.Deploy(ctx, plan, append(cfn.versionOptions(), deploy.WithStrategy(cfn.strategy), deploy.WithChunkSize(cfn.chunkSize))...); err != nil {
 		return fmt.Errorf("failed to deploy: %w", err)
 	}
 
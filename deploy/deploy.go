@@ -13,77 +13,294 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/shogo82148/schemalex-deploy"
 	"github.com/shogo82148/schemalex-deploy/diff"
+	"github.com/shogo82148/schemalex-deploy/model"
 )
 
 // DB is the target of deploying a DDL schema.
 type DB struct {
 	db *sql.DB
+
+	// version is the version of the server.
+	// it is nil if it is unknown.
+	version *model.ServerVersion
+
+	// name is the name of the database.
+	// it is empty if it is unknown.
+	name string
 }
 
-// Open opens a database specified by its database driver name.
+// Open opens a database specified by its database driver name,
+// and detects the version of the server.
+// Open uses context.Background internally; to specify the context, use OpenContext.
 func Open(driverName string, dataSourceName string) (*DB, error) {
+	return OpenContext(context.Background(), driverName, dataSourceName)
+}
+
+// OpenContext opens a database specified by its database driver name,
+// and detects the version of the server.
+func OpenContext(ctx context.Context, driverName string, dataSourceName string) (*DB, error) {
 	db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
 	}
+	version, err := getServerVersion(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to detect the server version: %w", err)
+	}
+	name, err := getDatabaseName(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to detect the database name: %w", err)
+	}
 	return &DB{
-		db: db,
+		db:      db,
+		version: &version,
+		name:    name,
 	}, nil
 }
 
+// ServerVersion returns the version of the server.
+// It returns model.DefaultServerVersion if the version is unknown.
+func (db *DB) ServerVersion() model.ServerVersion {
+	if db.version == nil {
+		return model.DefaultServerVersion
+	}
+	return *db.version
+}
+
+// diffOptions returns the options of diff.Diff for the server.
+func (db *DB) diffOptions(opts *myOptions, options ...diff.Option) []diff.Option {
+	version := opts.version
+	if version == nil {
+		version = db.version
+	}
+	if version != nil {
+		options = append(options, diff.WithServerVersion(*version))
+	}
+	if db.name != "" {
+		// the schema may select the database by USE.
+		options = append(options, diff.WithDatabase(db.name))
+	}
+	return options
+}
+
 // Close closes the database.
 func (db *DB) Close() error {
 	return db.db.Close()
 }
 
+var (
+	// ErrFromSchema is wrapped by the errors of planning when the schema that the plan migrates from can't be parsed.
+	ErrFromSchema = errors.New("failed to parse the latest schema")
+
+	// ErrToSchema is wrapped by the errors of planning when the schema that the plan migrates to can't be parsed.
+	ErrToSchema = errors.New("failed to parse the new schema")
+)
+
+// Plan is a series of statements to migrate from a schema to another one.
 type Plan struct {
+	// Revision is the ID of the schemalex_revision that the plan starts from.
+	// It is zero if the database is not initialized.
+	Revision uint64
+
 	From  string
 	To    string
 	Stmts diff.Stmts
+
+	// Rollback is a series of statements to migrate from To back to From.
+	// It is nil if the rollback plan can't be generated. See RollbackError.
+	Rollback diff.Stmts
+
+	// rollbackErr is the reason why the rollback plan can't be generated.
+	rollbackErr error
+
+	// source is the schema that the plan migrates from.
+	source Source
 }
 
 // Plan generates a series statements to migrate from the current one to the new schema.
-func (db *DB) Plan(ctx context.Context, schema string) (*Plan, error) {
+// By default, the current one is the latest revision recorded in schemalex_revision.
+// WithSource(SourceLive) makes it the live schema loaded by LoadSchema.
+func (db *DB) Plan(ctx context.Context, schema string, options ...Option) (*Plan, error) {
+	var opts myOptions
+	for _, o := range options {
+		o.apply(&opts)
+	}
+
 	latest, err := getLatestVersion(ctx, db.db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get the latest schema: %w", err)
 	}
 
-	p := schemalex.New()
-	opts := []diff.Option{
-		diff.WithTransaction(false),
-		diff.WithIndent(" ", 2),
+	if opts.source == SourceLive {
+		live, err := db.LoadSchema(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the live schema: %w", err)
+		}
+		plan, err := newPlan(live, schema, live, false, db.diffOptions(&opts)...)
+		if err != nil {
+			return nil, err
+		}
+		plan.Revision = latest.ID
+		plan.source = SourceLive
+		return plan, nil
 	}
 
+	return db.plan(ctx, latest.ID, latest.SQLText, schema, false, &opts)
+}
+
+// RollbackPlan generates a series statements to migrate from the current one to the previous revision.
+func (db *DB) RollbackPlan(ctx context.Context, options ...Option) (*Plan, error) {
+	var opts myOptions
+	for _, o := range options {
+		o.apply(&opts)
+	}
+
+	latest, err := getLatestVersion(ctx, db.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest schema: %w", err)
+	}
+	previous, err := getPreviousVersion(ctx, db.db, latest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the previous schema: %w", err)
+	}
+	return db.plan(ctx, latest.ID, latest.SQLText, previous.SQLText, true, &opts)
+}
+
+func (db *DB) plan(ctx context.Context, revision uint64, from, to string, isRollback bool, opts *myOptions) (*Plan, error) {
+	// the current schema is optional; it is used to guess the names of the indexes.
 	current, err := db.LoadSchema(ctx)
-	if err == nil {
-		opts = append(opts, diff.WithCurrentSchema(current))
+	if err != nil {
+		current = ""
 	}
 
-	stmts1, err := p.ParseString(latest.SQLText)
+	plan, err := newPlan(from, to, current, isRollback, db.diffOptions(opts)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse the latest schema: %w", err)
+		return nil, err
+	}
+	plan.Revision = revision
+	return plan, nil
+}
+
+// NewPlan generates a series statements to migrate from the schema to another one without databases.
+// It is useful to review migrations before any database is involved.
+// WithServerVersion makes the plan use the syntax appropriate for the server.
+func NewPlan(from, to string, options ...Option) (*Plan, error) {
+	var opts myOptions
+	for _, o := range options {
+		o.apply(&opts)
+	}
+	var diffOpts []diff.Option
+	if opts.version != nil {
+		diffOpts = append(diffOpts, diff.WithServerVersion(*opts.version))
+	}
+	return newPlan(from, to, "", false, diffOpts...)
+}
+
+// newPlan generates the plan that migrates from the schema to another one.
+// current is the schema deployed in MySQL, and it is empty if it is unknown.
+// If isRollback is true, the schema `to` is the previous revision of `from`,
+// and the plan renames back the tables and the columns renamed by `from`.
+// The options, such as the server version and the database, are applied to both the plan and its rollback.
+func newPlan(from, to, current string, isRollback bool, options ...diff.Option) (*Plan, error) {
+	p := schemalex.New()
+	opts := append([]diff.Option{
+		diff.WithTransaction(false),
+		diff.WithIndent(" ", 2),
+	}, options...)
+
+	stmts1, err := p.ParseString(from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFromSchema, err)
 	}
+	stmts1 = withoutRevisionTable(stmts1)
 
-	stmts2, err := p.ParseString(schema)
+	stmts2, err := p.ParseString(to)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse the new schema: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrToSchema, err)
+	}
+	stmts2 = withoutRevisionTable(stmts2)
+	if isRollback {
+		stmts2 = diff.InvertRenames(stmts2, stmts1)
 	}
 
-	stmts, err := diff.Diff(stmts1, stmts2, opts...)
+	var currentOpts []diff.Option
+	if current != "" {
+		currentOpts = append(currentOpts, diff.WithCurrentSchema(current))
+	}
+	stmts, err := diff.Diff(stmts1, stmts2, append(currentOpts, opts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to plan: %w", err)
 	}
 
+	// the current schema is the starting point of the forward plan,
+	// so it is not used for the rollback plan.
+	// the rollback plan is optional, so the deployment is not blocked by its errors.
+	// e.g. it can't drop the indexes without names that the plan adds.
+	// the renames by the annotations in the new schema are inverted, so that the rollback renames them back.
+	rollback, rollbackErr := diff.Diff(stmts2, diff.InvertRenames(stmts1, stmts2), opts...)
+	if rollbackErr != nil {
+		rollback = nil
+		rollbackErr = fmt.Errorf("failed to plan rollback: %w", rollbackErr)
+	}
+
 	return &Plan{
-		From:  latest.SQLText,
-		To:    schema,
-		Stmts: stmts,
+		From:        from,
+		To:          to,
+		Stmts:       stmts,
+		Rollback:    rollback,
+		rollbackErr: rollbackErr,
 	}, nil
 }
 
+// RollbackError returns the reason why the rollback plan can't be generated.
+// It returns nil if the rollback plan is available.
+func (plan *Plan) RollbackError() error {
+	return plan.rollbackErr
+}
+
+// Reverse returns the plan that migrates from plan.To to plan.From.
+func (plan *Plan) Reverse() *Plan {
+	return &Plan{
+		Revision: plan.Revision,
+		From:     plan.To,
+		To:       plan.From,
+		Stmts:    plan.Rollback,
+		Rollback: plan.Stmts,
+		source:   plan.source,
+	}
+}
+
+// Unrestorable returns the statements in the rollback plan that cannot restore the data lost by the plan.
+// e.g. the rollback plan re-creates the dropped tables and columns, but their rows are gone.
+func (plan *Plan) Unrestorable() diff.Stmts {
+	lost := make(map[string]struct{})
+	for _, stmt := range plan.Stmts.Filter(diff.ImpactDataLoss) {
+		lost[stmt.Table()] = struct{}{}
+	}
+
+	var ret diff.Stmts
+	for _, stmt := range plan.Rollback {
+		if _, ok := lost[stmt.Table()]; ok {
+			ret.Append(stmt)
+		}
+	}
+	return ret
+}
+
+// Destructive returns the statements that may lose data.
+func (plan *Plan) Destructive() diff.Stmts {
+	return plan.Stmts.Filter(diff.ImpactDataLoss)
+}
+
 func (plan *Plan) Preview(w io.Writer) error {
 	for _, stmt := range plan.Stmts {
+		if msg := impactWarning(stmt.Impact()); msg != "" {
+			if _, err := fmt.Fprintf(w, "-- WARNING: the following statement %s.\n", msg); err != nil {
+				return err
+			}
+		}
 		_, err := fmt.Fprintf(w, "%s;\n", stmt.String())
 		if err != nil {
 			return err
@@ -92,8 +309,67 @@ func (plan *Plan) Preview(w io.Writer) error {
 	return nil
 }
 
+// PreviewRollback writes the rollback plan to w.
+func (plan *Plan) PreviewRollback(w io.Writer) error {
+	if plan.rollbackErr != nil {
+		_, err := fmt.Fprintf(w, "-- WARNING: %s\n", plan.rollbackErr)
+		return err
+	}
+	unrestorable := plan.unrestorableSet()
+	for _, stmt := range plan.Rollback {
+		if msg := impactWarning(stmt.Impact()); msg != "" {
+			if _, err := fmt.Fprintf(w, "-- WARNING: the following statement %s.\n", msg); err != nil {
+				return err
+			}
+		}
+		if _, ok := unrestorable[stmt.String()]; ok {
+			if _, err := fmt.Fprintf(w, "-- WARNING: the following statement %s.\n", unrestorableWarning); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "%s;\n", stmt.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (plan *Plan) unrestorableSet() map[string]struct{} {
+	unrestorable := make(map[string]struct{})
+	for _, stmt := range plan.Unrestorable() {
+		unrestorable[stmt.String()] = struct{}{}
+	}
+	return unrestorable
+}
+
+const unrestorableWarning = "cannot restore the data lost by the deploy"
+
+// impactWarning returns the warning message about the impact.
+// It returns an empty string if the impact is safe.
+func impactWarning(impact diff.Impact) string {
+	switch impact {
+	case diff.ImpactDataLoss:
+		return "may lose data"
+	case diff.ImpactLockHeavy:
+		return "may lock the table for a long time"
+	}
+	return ""
+}
+
 // Deploy deploys the new schema according to the plan.
-func (db *DB) Deploy(ctx context.Context, plan *Plan) error {
+func (db *DB) Deploy(ctx context.Context, plan *Plan, options ...Option) error {
+	opts := myOptions{
+		strategy:  StrategyDirect,
+		chunkSize: defaultChunkSize,
+	}
+	for _, o := range options {
+		o.apply(&opts)
+	}
+	if opts.version == nil {
+		opts.version = db.version
+	}
+
 	log.Printf("starting to deploy")
 
 	tx, err := db.db.BeginTx(ctx, nil)
@@ -106,7 +382,12 @@ func (db *DB) Deploy(ctx context.Context, plan *Plan) error {
 	if err != nil {
 		return fmt.Errorf("failed to get the latest version: %w", err)
 	}
-	if latest.SQLText != plan.From {
+	if plan.source == SourceLive {
+		// the plan is not based on the revision, but no one should deploy after planning.
+		if latest.ID != plan.Revision {
+			return errors.New("detected unexpected change")
+		}
+	} else if latest.SQLText != plan.From {
 		return errors.New("detected unexpected change")
 	}
 
@@ -119,9 +400,8 @@ func (db *DB) Deploy(ctx context.Context, plan *Plan) error {
 
 	// migration
 	for _, stmt := range plan.Stmts {
-		log.Printf("executing: %s", stmt.String())
-		if _, err := tx.ExecContext(ctx, stmt.String()); err != nil {
-			return fmt.Errorf("failed to execute %q: %w", stmt.String(), err)
+		if err := execStmt(ctx, tx, stmt, &opts); err != nil {
+			return err
 		}
 	}
 	log.Printf("updating the schema information")
@@ -144,6 +424,67 @@ func (db *DB) Deploy(ctx context.Context, plan *Plan) error {
 	return nil
 }
 
+// Drift compares the latest revision in schemalex_revision with the live schema.
+// The returned plan migrates from the recorded schema to the live one,
+// so its statements are empty if no one changed the schema outside of schemalex-deploy.
+// Its rollback reverts the changes; the plan itself is a report, and it is not intended to be deployed.
+func (db *DB) Drift(ctx context.Context) (*Plan, error) {
+	latest, err := getLatestVersion(ctx, db.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest schema: %w", err)
+	}
+
+	live, err := db.LoadSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the live schema: %w", err)
+	}
+
+	p := schemalex.New()
+	recorded, err := p.ParseString(latest.SQLText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the latest schema: %w", err)
+	}
+	current, err := p.ParseString(live)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the live schema: %w", err)
+	}
+	recorded = withoutRevisionTable(recorded)
+	current = withoutRevisionTable(current)
+
+	opts := db.diffOptions(&myOptions{},
+		diff.WithTransaction(false),
+		diff.WithIndent(" ", 2),
+	)
+	stmts, err := diff.Diff(recorded, current, append(opts, diff.WithCurrentSchema(live))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare the schemas: %w", err)
+	}
+	rollback, err := diff.Diff(current, recorded, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare the schemas: %w", err)
+	}
+
+	return &Plan{
+		Revision: latest.ID,
+		From:     latest.SQLText,
+		To:       live,
+		Stmts:    stmts,
+		Rollback: rollback,
+	}, nil
+}
+
+// withoutRevisionTable removes the schemalex_revision table, which is managed by schemalex-deploy itself.
+func withoutRevisionTable(stmts model.Stmts) model.Stmts {
+	ret := make(model.Stmts, 0, len(stmts))
+	for _, stmt := range stmts {
+		if table, ok := stmt.(*model.Table); ok && table.Name == "schemalex_revision" {
+			continue
+		}
+		ret = append(ret, stmt)
+	}
+	return ret
+}
+
 // LoadSchema loads existing table schemas from running database.
 func (db *DB) LoadSchema(ctx context.Context) (string, error) {
 	tx, err := db.db.BeginTx(ctx, &sql.TxOptions{
@@ -154,18 +495,40 @@ func (db *DB) LoadSchema(ctx context.Context) (string, error) {
 	}
 	defer tx.Commit()
 
-	tables, err := showTables(ctx, tx)
+	tables, views, err := showTables(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+
+	routines, err := showRoutines(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+
+	triggers, err := showTriggers(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+
+	events, err := showEvents(ctx, tx)
 	if err != nil {
 		return "", err
 	}
 
-	if len(tables) == 0 {
+	if len(tables) == 0 && len(views) == 0 && len(routines) == 0 && len(triggers) == 0 && len(events) == 0 {
 		return "", nil
 	}
 
+	database, err := db.showDatabase(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+
 	statements := []string{
 		"SET FOREIGN_KEY_CHECKS = 0;",
 		"", // blank line
+		database,
+		"", // blank line
 	}
 
 	for _, tbl := range tables {
@@ -190,6 +553,99 @@ func (db *DB) LoadSchema(ctx context.Context) (string, error) {
 		)
 	}
 
+	// the routine bodies contain semicolons, so the delimiter is changed.
+	// the views may call the stored functions, so they are imported before the views.
+	for _, r := range routines {
+		if !r.visible {
+			// the user doesn't have enough privileges to see the definition.
+			log.Printf("WARNING: skip importing %s %s: the definition is not visible", strings.ToLower(r.typ), r.name)
+			continue
+		}
+		row := tx.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE %s `%s`", r.typ, r.name))
+		var tmp, sqlMode, charset, collation, dbCollation string
+		var sqlText sql.NullString
+		if err := row.Scan(&tmp, &sqlMode, &sqlText, &charset, &collation, &dbCollation); err != nil {
+			return "", fmt.Errorf("failed to get create %s %q: %w", strings.ToLower(r.typ), r.name, err)
+		}
+		if !sqlText.Valid {
+			log.Printf("WARNING: skip importing %s %s: the definition is not visible", strings.ToLower(r.typ), r.name)
+			continue
+		}
+
+		log.Printf("import %s: %s", strings.ToLower(r.typ), r.name)
+		statements = append(statements,
+			fmt.Sprintf("DROP %s IF EXISTS `%s`;", r.typ, r.name),
+			"", // blank line
+			"DELIMITER ;;",
+			sqlText.String+";;",
+			"DELIMITER ;",
+			"", // blank line
+		)
+	}
+
+	for _, view := range views {
+		log.Printf("import view: %s", view)
+		statements = append(statements,
+			fmt.Sprintf("DROP VIEW IF EXISTS `%s`;", view),
+			"", // blank line
+		)
+		row := tx.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE VIEW `%s`", view))
+		var tmp, sqlText, charset, collation string
+		if err := row.Scan(&tmp, &sqlText, &charset, &collation); err != nil {
+			return "", fmt.Errorf("failed to get create view %q: %w", view, err)
+		}
+
+		if !strings.HasSuffix(sqlText, ";") {
+			sqlText = sqlText + ";"
+		}
+
+		statements = append(statements,
+			sqlText,
+			"", // blank line
+		)
+	}
+
+	for _, trigger := range triggers {
+		log.Printf("import trigger: %s", trigger)
+		statements = append(statements,
+			fmt.Sprintf("DROP TRIGGER IF EXISTS `%s`;", trigger),
+			"", // blank line
+		)
+		row := tx.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE TRIGGER `%s`", trigger))
+		var tmp, sqlMode, sqlText, charset, collation, dbCollation string
+		var created sql.NullString
+		if err := row.Scan(&tmp, &sqlMode, &sqlText, &charset, &collation, &dbCollation, &created); err != nil {
+			return "", fmt.Errorf("failed to get create trigger %q: %w", trigger, err)
+		}
+
+		statements = append(statements,
+			"DELIMITER ;;",
+			sqlText+";;",
+			"DELIMITER ;",
+			"", // blank line
+		)
+	}
+
+	for _, event := range events {
+		log.Printf("import event: %s", event)
+		statements = append(statements,
+			fmt.Sprintf("DROP EVENT IF EXISTS `%s`;", event),
+			"", // blank line
+		)
+		row := tx.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE EVENT `%s`", event))
+		var tmp, sqlMode, timeZone, sqlText, charset, collation, dbCollation string
+		if err := row.Scan(&tmp, &sqlMode, &timeZone, &sqlText, &charset, &collation, &dbCollation); err != nil {
+			return "", fmt.Errorf("failed to get create event %q: %w", event, err)
+		}
+
+		statements = append(statements,
+			"DELIMITER ;;",
+			sqlText+";;",
+			"DELIMITER ;",
+			"", // blank line
+		)
+	}
+
 	statements = append(statements, "SET FOREIGN_KEY_CHECKS = 1;")
 
 	return strings.Join(statements, "\n"), nil
@@ -227,7 +683,26 @@ type schemalexRevision struct {
 	UpgradedAt time.Time
 }
 
-// get the latest version of schema out of a transaction.
+// getServerVersion returns the version of the server.
+func getServerVersion(ctx context.Context, db *sql.DB) (model.ServerVersion, error) {
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return model.ServerVersion{}, err
+	}
+	return model.ParseServerVersion(version)
+}
+
+// getDatabaseName returns the name of the current database.
+// It returns an empty string if no database is selected.
+func getDatabaseName(ctx context.Context, db *sql.DB) (string, error) {
+	var name sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&name); err != nil {
+		return "", err
+	}
+	return name.String, nil
+}
+
+// getLatestVersion returns the latest revision of the schema out of a transaction.
 func getLatestVersion(ctx context.Context, db *sql.DB) (*schemalexRevision, error) {
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{
 		ReadOnly: true,
@@ -269,6 +744,46 @@ func getLatestVersionTx(ctx context.Context, tx *sql.Tx) (*schemalexRevision, er
 	return &rev, nil
 }
 
+// getPreviousVersion returns the revision that the latest one was deployed on.
+// A revision that restores the revision before its predecessor is regarded as a rollback,
+// and it cancels the predecessor out. So rolling back repeatedly goes further back
+// instead of flipping between the last two revisions.
+func getPreviousVersion(ctx context.Context, db *sql.DB, latest *schemalexRevision) (*schemalexRevision, error) {
+	rows, err := db.QueryContext(ctx, "SELECT `id`, `sql_text`, `upgraded_at` FROM `schemalex_revision` WHERE `id` <= ? ORDER BY `id` ASC", latest.ID)
+	if err != nil {
+		var myerr *mysql.MySQLError
+		if errors.As(err, &myerr) {
+			if myerr.Number == 1146 { // = ER_NO_SUCH_TABLE: Table 'schemalex_revision' doesn't exist
+				return nil, errors.New("no previous revision found")
+			}
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stack []*schemalexRevision
+	for rows.Next() {
+		var rev schemalexRevision
+		if err := rows.Scan(&rev.ID, &rev.SQLText, &rev.UpgradedAt); err != nil {
+			return nil, err
+		}
+		if n := len(stack); n >= 2 && stack[n-2].SQLText == rev.SQLText {
+			// rev rolls back stack[n-1].
+			stack = stack[:n-1]
+			continue
+		}
+		stack = append(stack, &rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(stack) < 2 {
+		return nil, errors.New("no previous revision found")
+	}
+	return stack[len(stack)-2], nil
+}
+
 // update the schema information.
 func updateLatestVersion(ctx context.Context, tx *sql.Tx, rev *schemalexRevision) error {
 	createTable := "CREATE TABLE IF NOT EXISTS `schemalex_revision` ( " +
@@ -288,24 +803,142 @@ func updateLatestVersion(ctx context.Context, tx *sql.Tx, rev *schemalexRevision
 	return nil
 }
 
-func showTables(ctx context.Context, tx *sql.Tx) ([]string, error) {
-	rows, err := tx.QueryContext(ctx, "SHOW TABLES")
+// showTables returns the names of the tables and the views in the database.
+func showTables(ctx context.Context, tx *sql.Tx) (tables, views []string, err error) {
+	rows, err := tx.QueryContext(ctx, "SHOW FULL TABLES")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get table list: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		switch typ {
+		case "BASE TABLE":
+			tables = append(tables, name)
+		case "VIEW":
+			views = append(views, name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("some error occurred during iteration: %w", err)
+	}
+	return tables, views, nil
+}
+
+type routine struct {
+	typ  string // PROCEDURE or FUNCTION
+	name string
+
+	// visible is false if the user doesn't have enough privileges to see the definition.
+	visible bool
+}
+
+// showRoutines returns the stored procedures and functions in the database.
+func showRoutines(ctx context.Context, tx *sql.Tx) ([]routine, error) {
+	query := "SELECT `ROUTINE_TYPE`, `ROUTINE_NAME`, `ROUTINE_DEFINITION` IS NOT NULL FROM `information_schema`.`ROUTINES` " +
+		"WHERE `ROUTINE_SCHEMA` = DATABASE() ORDER BY `ROUTINE_TYPE`, `ROUTINE_NAME`"
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routine list: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []routine
+	for rows.Next() {
+		var r routine
+		if err := rows.Scan(&r.typ, &r.name, &r.visible); err != nil {
+			return nil, fmt.Errorf("failed to scan routine name: %w", err)
+		}
+		routines = append(routines, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("some error occurred during iteration: %w", err)
+	}
+	return routines, nil
+}
+
+// showTriggers returns the names of the triggers in the database.
+// They are sorted in the order of execution for each table, so FOLLOWS and PRECEDES are not necessary.
+func showTriggers(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	query := "SELECT `TRIGGER_NAME` FROM `information_schema`.`TRIGGERS` " +
+		"WHERE `TRIGGER_SCHEMA` = DATABASE() " +
+		"ORDER BY `EVENT_OBJECT_TABLE`, `ACTION_TIMING`, `EVENT_MANIPULATION`, `ACTION_ORDER`"
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trigger list: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger name: %w", err)
+		}
+		triggers = append(triggers, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("some error occurred during iteration: %w", err)
+	}
+	return triggers, nil
+}
+
+// showDatabase returns the CREATE DATABASE statement of the current database.
+// It has only the default options, so it can be compared with the database in the schema.
+func (db *DB) showDatabase(ctx context.Context, tx *sql.Tx) (string, error) {
+	encryption := db.version != nil && db.version.SupportsDefaultEncryption()
+	query := "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME`"
+	if encryption {
+		query += ", `DEFAULT_ENCRYPTION`"
+	}
+	query += " FROM `information_schema`.`SCHEMATA` WHERE `SCHEMA_NAME` = DATABASE()"
+
+	var name, charset, collation, defaultEncryption string
+	dest := []interface{}{&name, &charset, &collation}
+	if encryption {
+		dest = append(dest, &defaultEncryption)
+	}
+	if err := tx.QueryRowContext(ctx, query).Scan(dest...); err != nil {
+		return "", fmt.Errorf("failed to get the database options: %w", err)
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "CREATE DATABASE IF NOT EXISTS `%s` DEFAULT CHARACTER SET = %s DEFAULT COLLATE = %s", name, charset, collation)
+	if encryption {
+		fmt.Fprintf(&buf, " DEFAULT ENCRYPTION = '%s'", defaultEncryption)
+	}
+	buf.WriteByte(';')
+	return buf.String(), nil
+}
+
+// showEvents returns the names of the events in the database.
+func showEvents(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	query := "SELECT `EVENT_NAME` FROM `information_schema`.`EVENTS` " +
+		"WHERE `EVENT_SCHEMA` = DATABASE() ORDER BY `EVENT_NAME`"
+	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get table list: %w", err)
+		return nil, fmt.Errorf("failed to get event list: %w", err)
 	}
 	defer rows.Close()
 
-	var tables []string
+	var events []string
 	for rows.Next() {
-		var table string
-		if err := rows.Scan(&table); err != nil {
-			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan event name: %w", err)
 		}
-		tables = append(tables, table)
+		events = append(events, name)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("some error occurred during iteration: %w", err)
 	}
-	return tables, nil
+	return events, nil
 }
@@ -3,11 +3,15 @@ package deploy
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/diff"
 	"github.com/shogo82148/schemalex-deploy/internal/database"
 	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
 )
 
 func TestDeploy(t *testing.T) {
@@ -189,4 +193,548 @@ func TestImport(t *testing.T) {
 			t.Errorf("want no diff is detected, but not: %v", plan.Stmts)
 		}
 	})
+
+	t.Run("after create routine", func(t *testing.T) {
+		fn := "CREATE FUNCTION add_one (a INT) RETURNS INT DETERMINISTIC BEGIN RETURN a + 1; END"
+		if _, err := db.db.ExecContext(ctx, fn); err != nil {
+			t.Fatalf("failed to create `add_one` function: %v", err)
+		}
+
+		sqlText, err := db.LoadSchema(ctx)
+		if err != nil {
+			t.Fatalf("failed to load schema: %v", err)
+		}
+		stmts, err := schemalex.New().ParseString(sqlText)
+		if err != nil {
+			t.Fatalf("failed to parse the schema: %v", err)
+		}
+		if _, ok := stmts.Lookup("function#add_one"); !ok {
+			t.Errorf("want function `add_one` is loaded, but not: %s", sqlText)
+		}
+	})
+
+	t.Run("after create trigger", func(t *testing.T) {
+		tr := "CREATE TRIGGER hoge_bi BEFORE INSERT ON hoge FOR EACH ROW BEGIN SET NEW.c = UPPER(NEW.c); END"
+		if _, err := db.db.ExecContext(ctx, tr); err != nil {
+			t.Fatalf("failed to create `hoge_bi` trigger: %v", err)
+		}
+
+		sqlText, err := db.LoadSchema(ctx)
+		if err != nil {
+			t.Fatalf("failed to load schema: %v", err)
+		}
+		stmts, err := schemalex.New().ParseString(sqlText)
+		if err != nil {
+			t.Fatalf("failed to parse the schema: %v", err)
+		}
+		if _, ok := stmts.Lookup("trigger#hoge_bi"); !ok {
+			t.Errorf("want trigger `hoge_bi` is loaded, but not: %s", sqlText)
+		}
+	})
+
+	t.Run("after create event", func(t *testing.T) {
+		ev := "CREATE EVENT purge_hoge ON SCHEDULE EVERY 1 DAY DISABLE DO DELETE FROM hoge"
+		if _, err := db.db.ExecContext(ctx, ev); err != nil {
+			t.Fatalf("failed to create `purge_hoge` event: %v", err)
+		}
+
+		sqlText, err := db.LoadSchema(ctx)
+		if err != nil {
+			t.Fatalf("failed to load schema: %v", err)
+		}
+		stmts, err := schemalex.New().ParseString(sqlText)
+		if err != nil {
+			t.Fatalf("failed to parse the schema: %v", err)
+		}
+		if _, ok := stmts.Lookup("event#purge_hoge"); !ok {
+			t.Errorf("want event `purge_hoge` is loaded, but not: %s", sqlText)
+		}
+	})
+
+	t.Run("database options", func(t *testing.T) {
+		var name string
+		if err := db.db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&name); err != nil {
+			t.Fatalf("failed to get the database name: %v", err)
+		}
+		if _, err := db.db.ExecContext(ctx, "ALTER DATABASE `"+name+"` CHARACTER SET latin1 COLLATE latin1_bin"); err != nil {
+			t.Fatalf("failed to alter the database: %v", err)
+		}
+
+		sqlText, err := db.LoadSchema(ctx)
+		if err != nil {
+			t.Fatalf("failed to load schema: %v", err)
+		}
+		stmts, err := schemalex.New().ParseString(sqlText)
+		if err != nil {
+			t.Fatalf("failed to parse the schema: %v", err)
+		}
+		if _, ok := stmts.Lookup("database#" + strings.ToLower(name)); !ok {
+			t.Errorf("want database %q is loaded, but not: %s", name, sqlText)
+		}
+
+		schema := strings.Replace(sqlText,
+			"DEFAULT CHARACTER SET = latin1 DEFAULT COLLATE = latin1_bin",
+			"DEFAULT CHARACTER SET = utf8mb4 DEFAULT COLLATE = utf8mb4_bin", 1)
+		plan, err := db.Plan(ctx, schema, WithSource(SourceLive))
+		if err != nil {
+			t.Fatalf("failed to plan: %v", err)
+		}
+		want := "ALTER DATABASE `" + name + "` CHARACTER SET = utf8mb4 COLLATE = utf8mb4_bin"
+		if len(plan.Stmts) != 1 || plan.Stmts[0].String() != want {
+			t.Errorf("want %q, but got %v", want, plan.Stmts)
+		}
+	})
+}
+
+// newTestPlan generates the plan without databases.
+func newTestPlan(t *testing.T, from, to string) *Plan {
+	t.Helper()
+	p := schemalex.New()
+	stmts1, err := p.ParseString(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts2, err := p.ParseString(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forward, err := diff.Diff(stmts1, stmts2, diff.WithTransaction(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollback, err := diff.Diff(stmts2, stmts1, diff.WithTransaction(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Plan{
+		From:     from,
+		To:       to,
+		Stmts:    forward,
+		Rollback: rollback,
+	}
+}
+
+func TestPlan_Unrestorable(t *testing.T) {
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, PRIMARY KEY (`id`) );\n" +
+		"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );\n" +
+		"CREATE TABLE `piyo` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );\n" +
+		"CREATE TABLE `piyo` ( `id` INTEGER NOT NULL, `b` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+
+	plan := newTestPlan(t, from, to)
+
+	var got []string
+	for _, stmt := range plan.Unrestorable() {
+		got = append(got, stmt.String())
+	}
+	want := []string{
+		"CREATE TABLE `fuga` (\n`id` INT (11) NOT NULL,\nPRIMARY KEY (`id`)\n)",
+		"ALTER TABLE `hoge` ADD COLUMN `a` INT (11) NOT NULL AFTER `id`",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unrestorable statements mismatch (-want,+got):\n%s", diff)
+	}
+
+	// dropping the column `b` loses data, but the forward plan can restore the schema.
+	reverse := plan.Reverse()
+	got = got[:0]
+	for _, stmt := range reverse.Unrestorable() {
+		got = append(got, stmt.String())
+	}
+	want = []string{
+		"ALTER TABLE `piyo` ADD COLUMN `b` INT (11) NOT NULL AFTER `id`",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unrestorable statements mismatch (-want,+got):\n%s", diff)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	database.SkipIfNoTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rawDB, cleanup := database.SetupTestDB()
+	defer cleanup()
+	db := &DB{
+		db: rawDB,
+	}
+
+	if _, err := db.RollbackPlan(ctx); err == nil {
+		t.Error("want error, but not")
+	}
+
+	const schema1 = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, PRIMARY KEY (id) );"
+	const schema2 = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, c VARCHAR (20) NOT NULL, PRIMARY KEY (id) );"
+	for _, schema := range []string{schema1, schema2} {
+		plan, err := db.Plan(ctx, schema)
+		if err != nil {
+			t.Fatalf("failed to plan: %v", err)
+		}
+		if err := db.Deploy(ctx, plan); err != nil {
+			t.Fatalf("failed to deploy: %v", err)
+		}
+	}
+
+	plan, err := db.RollbackPlan(ctx)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if plan.From != schema2 || plan.To != schema1 {
+		t.Errorf("unexpected plan: from %q, to %q", plan.From, plan.To)
+	}
+	if err := db.Deploy(ctx, plan); err != nil {
+		t.Fatalf("failed to deploy: %v", err)
+	}
+
+	hoge, err := showColumns(ctx, db.db, "hoge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hoge) != 1 {
+		t.Errorf("want `hoge` has one column, but %d columns", len(hoge))
+	}
+
+	// the revision of the rollback cancels out schema2, so there is no more revision to roll back.
+	if _, err := db.RollbackPlan(ctx); err == nil {
+		t.Error("want error, but not")
+	}
+}
+
+func TestRollback_Rename(t *testing.T) {
+	database.SkipIfNoTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rawDB, cleanup := database.SetupTestDB()
+	defer cleanup()
+	db := &DB{
+		db: rawDB,
+	}
+
+	const schema1 = "CREATE TABLE hoge ( id INTEGER NOT NULL, a INTEGER NOT NULL, PRIMARY KEY (id) );"
+	const schema2 = "-- schemalex:renamed-from hoge\n" +
+		"CREATE TABLE fuga ( id INTEGER NOT NULL, -- schemalex:renamed-from a\nb INTEGER NOT NULL, PRIMARY KEY (id) );"
+	for _, schema := range []string{schema1, schema2} {
+		plan, err := db.Plan(ctx, schema)
+		if err != nil {
+			t.Fatalf("failed to plan: %v", err)
+		}
+		if err := db.Deploy(ctx, plan); err != nil {
+			t.Fatalf("failed to deploy: %v", err)
+		}
+		if schema == schema1 {
+			if _, err := db.db.ExecContext(ctx, "INSERT INTO hoge (id, a) VALUES (1, 2)"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	plan, err := db.RollbackPlan(ctx)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if err := db.Deploy(ctx, plan); err != nil {
+		t.Fatalf("failed to deploy: %v", err)
+	}
+
+	// the rows survive the rollback.
+	var a int
+	if err := db.db.QueryRowContext(ctx, "SELECT a FROM hoge WHERE id = 1").Scan(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a != 2 {
+		t.Errorf("want 2, got %d", a)
+	}
+}
+
+func TestNewPlan_RollbackOptions(t *testing.T) {
+	t.Run("database", func(t *testing.T) {
+		// the live schema doesn't select the database, but the new schema does.
+		const from = "CREATE TABLE `t` ( `id` INTEGER NOT NULL );"
+		const to = "USE gotest;\nCREATE TABLE `t` ( `id` INTEGER NOT NULL );"
+		plan, err := newPlan(from, to, "", false, diff.WithDatabase("gotest"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Stmts) != 0 {
+			t.Errorf("want no statement, got %v", plan.Stmts)
+		}
+		if len(plan.Rollback) != 0 {
+			t.Errorf("want no rollback, got %v", plan.Rollback)
+		}
+	})
+
+	t.Run("server version", func(t *testing.T) {
+		const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL );"
+		const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` INTEGER NOT NULL );"
+		plan, err := NewPlan(from, to, WithServerVersion(model.ServerVersionMySQL80))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf strings.Builder
+		if err := plan.PreviewRollback(&buf); err != nil {
+			t.Fatal(err)
+		}
+		want := "ALTER TABLE `hoge` RENAME COLUMN `b` TO `a`;\n"
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+		}
+	})
+}
+
+func TestNewPlan_RollbackRevision(t *testing.T) {
+	const latest = "-- schemalex:renamed-from hoge\n" +
+		"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` INTEGER NOT NULL );"
+	const previous = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL );"
+	plan, err := newPlan(latest, previous, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := plan.Preview(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "RENAME TABLE `fuga` TO `hoge`;\n" +
+		"ALTER TABLE `hoge` CHANGE COLUMN `b` `a` INT (11) NOT NULL;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := plan.PreviewRollback(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want = "RENAME TABLE `hoge` TO `fuga`;\n" +
+		"ALTER TABLE `fuga` CHANGE COLUMN `a` `b` INT (11) NOT NULL;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `c` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	plan, err := NewPlan(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := plan.Preview(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "ALTER TABLE `hoge` ADD COLUMN `c` INT (11) NOT NULL AFTER `id`;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := plan.PreviewRollback(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want = "-- WARNING: the following statement may lose data.\n" +
+		"ALTER TABLE `hoge` DROP COLUMN `c`;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+	}
+
+	if _, err := NewPlan(from, "CREATE TABLE"); err == nil {
+		t.Error("want error, but not")
+	}
+}
+
+func TestNewPlan_WithServerVersion(t *testing.T) {
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	plan, err := NewPlan(from, to, WithServerVersion(model.ServerVersionMySQL80))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := plan.Preview(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "ALTER TABLE `hoge` RENAME COLUMN `a` TO `b`;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	database.SkipIfNoTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rawDB, cleanup := database.SetupTestDB()
+	defer cleanup()
+	db := &DB{
+		db: rawDB,
+	}
+
+	const schema = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, PRIMARY KEY (id) ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4;"
+	plan, err := db.Plan(ctx, schema)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if err := db.Deploy(ctx, plan); err != nil {
+		t.Fatalf("failed to deploy: %v", err)
+	}
+
+	drift, err := db.Drift(ctx)
+	if err != nil {
+		t.Fatalf("failed to check drift: %v", err)
+	}
+	if len(drift.Stmts) != 0 {
+		t.Errorf("want no drift, got %v", drift.Stmts)
+	}
+
+	// change the schema by hand
+	if _, err := db.db.ExecContext(ctx, "ALTER TABLE hoge ADD COLUMN c INTEGER NOT NULL"); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err = db.Drift(ctx)
+	if err != nil {
+		t.Fatalf("failed to check drift: %v", err)
+	}
+	if len(drift.Stmts) != 1 || drift.Stmts[0].Kind() != diff.StmtKindAlterTable {
+		t.Errorf("want drift of hoge, got %v", drift.Stmts)
+	}
+	if len(drift.Rollback) != 1 || drift.Rollback[0].Impact() != diff.ImpactDataLoss {
+		t.Errorf("want rollback to drop the column, got %v", drift.Rollback)
+	}
+}
+
+func TestWithoutRevisionTable(t *testing.T) {
+	stmts, err := schemalex.New().ParseString("CREATE TABLE `hoge` ( `id` INTEGER NOT NULL );\n" +
+		"CREATE TABLE `schemalex_revision` ( `id` BIGINT unsigned NOT NULL );")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := withoutRevisionTable(stmts)
+	if len(got) != 1 || got[0].ID() != "table#hoge" {
+		t.Errorf("unexpected statements: %v", got)
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want Source
+	}{
+		{"revision", SourceRevision},
+		{"LIVE", SourceLive},
+	} {
+		got, err := ParseSource(tt.in)
+		if err != nil {
+			t.Errorf("ParseSource(%q) returns error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSource(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseSource("unknown"); err == nil {
+		t.Error("want error, but not")
+	}
+}
+
+func TestNewPlan_RollbackError(t *testing.T) {
+	// the rollback plan can't drop the anonymous index, because its name is unknown.
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL );"
+	const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, INDEX (`a`, `id`) );"
+	plan, err := NewPlan(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Stmts) != 1 {
+		t.Errorf("want 1 statement, got %d", len(plan.Stmts))
+	}
+	if plan.RollbackError() == nil {
+		t.Error("want rollback error, but not")
+	}
+	if plan.Rollback != nil {
+		t.Errorf("want no rollback, got %v", plan.Rollback)
+	}
+}
+
+func TestNewPlan_RollbackRenames(t *testing.T) {
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL );"
+	const to = "-- schemalex:renamed-from hoge\n" +
+		"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` INTEGER NOT NULL );"
+	plan, err := NewPlan(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := plan.PreviewRollback(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "RENAME TABLE `fuga` TO `hoge`;\n" +
+		"ALTER TABLE `hoge` CHANGE COLUMN `b` `a` INT (11) NOT NULL;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("preview mismatch (-want,+got):\n%s", diff)
+	}
+}
+
+func TestNewPlan_WithoutRevisionTable(t *testing.T) {
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL );\n" +
+		"CREATE TABLE `schemalex_revision` ( `id` BIGINT unsigned NOT NULL );"
+	const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL );"
+	plan, err := NewPlan(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Stmts) != 0 {
+		t.Errorf("want no statement, got %v", plan.Stmts)
+	}
+}
+
+func TestPlanFromLive(t *testing.T) {
+	database.SkipIfNoTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rawDB, cleanup := database.SetupTestDB()
+	defer cleanup()
+	db := &DB{
+		db: rawDB,
+	}
+
+	// the tables are created without schemalex-deploy.
+	queries := []string{
+		"CREATE TABLE fuga ( id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY, c CHAR(10) CHARACTER SET ascii ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4",
+		"CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, fuga_id BIGINT UNSIGNED NOT NULL, " +
+			"PRIMARY KEY (id), FOREIGN KEY (fuga_id) REFERENCES fuga (id) ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4",
+	}
+	for _, q := range queries {
+		if _, err := db.db.ExecContext(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan, err := db.Plan(ctx, strings.Join(queries, ";\n")+";", WithSource(SourceLive))
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if len(plan.Stmts) != 0 {
+		t.Errorf("want no statement, got %v", plan.Stmts)
+	}
+	if err := db.Deploy(ctx, plan); err != nil {
+		t.Fatalf("failed to deploy: %v", err)
+	}
+
+	latest, err := getLatestVersion(ctx, db.db)
+	if err != nil {
+		t.Fatalf("failed to get the latest version: %v", err)
+	}
+	if latest.SQLText != plan.To {
+		t.Errorf("want %q, got %q", plan.To, latest.SQLText)
+	}
 }
@@ -0,0 +1,127 @@
+package deploy
+
+import (
+	"encoding/json"
+
+	"github.com/shogo82148/schemalex-deploy/diff"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+type jsonPlan struct {
+	Revision    uint64      `json:"revision"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Impact      diff.Impact `json:"impact"`
+	Destructive int         `json:"destructive"`
+	Stmts       []jsonStmt  `json:"statements"`
+	Rollback    []jsonStmt  `json:"rollback"`
+	Warnings    []string    `json:"warnings"`
+}
+
+type jsonStmt struct {
+	SQL      string        `json:"sql"`
+	Kind     diff.StmtKind `json:"kind"`
+	Table    string        `json:"table,omitempty"`
+	Impact   diff.Impact   `json:"impact"`
+	Clauses  []jsonClause  `json:"clauses,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+type jsonClause struct {
+	SQL    string          `json:"sql"`
+	Kind   diff.ClauseKind `json:"kind"`
+	Impact diff.Impact     `json:"impact"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// It is intended to be consumed by review bots, so it contains
+// the kinds, the target tables, the impacts and the warnings of the statements.
+func (plan *Plan) MarshalJSON() ([]byte, error) {
+	v := jsonPlan{
+		Revision:    plan.Revision,
+		From:        plan.From,
+		To:          plan.To,
+		Impact:      plan.Stmts.Impact(),
+		Stmts:       []jsonStmt{},
+		Rollback:    []jsonStmt{},
+		Warnings:    []string{},
+		Destructive: len(plan.Destructive()),
+	}
+
+	for _, stmt := range plan.Stmts {
+		s := newJSONStmt(stmt)
+		if msg := impactWarning(stmt.Impact()); msg != "" {
+			s.Warnings = append(s.Warnings, "the statement "+msg)
+		}
+		v.Stmts = append(v.Stmts, s)
+	}
+
+	unrestorable := plan.unrestorableSet()
+	for _, stmt := range plan.Rollback {
+		s := newJSONStmt(stmt)
+		if msg := impactWarning(stmt.Impact()); msg != "" {
+			s.Warnings = append(s.Warnings, "the statement "+msg)
+		}
+		if _, ok := unrestorable[stmt.String()]; ok {
+			s.Warnings = append(s.Warnings, "the statement "+unrestorableWarning)
+		}
+		v.Rollback = append(v.Rollback, s)
+	}
+
+	if v.Destructive > 0 {
+		v.Warnings = append(v.Warnings, "the plan contains statements that may lose data")
+	}
+	if plan.rollbackErr != nil {
+		v.Warnings = append(v.Warnings, plan.rollbackErr.Error())
+	}
+	if len(unrestorable) > 0 {
+		v.Warnings = append(v.Warnings, "the rollback plan cannot restore the data lost by the deploy")
+	}
+
+	return json.Marshal(v)
+}
+
+func newJSONStmt(stmt diff.Stmt) jsonStmt {
+	s := jsonStmt{
+		SQL:    stmt.String(),
+		Kind:   stmt.Kind(),
+		Table:  tableName(stmt),
+		Impact: stmt.Impact(),
+	}
+	for _, c := range stmt.Clauses() {
+		s.Clauses = append(s.Clauses, jsonClause{
+			SQL:    c.String(),
+			Kind:   c.Kind(),
+			Impact: c.Impact(),
+		})
+	}
+	return s
+}
+
+// tableName returns the name of the table, the view, the routine, the event or the database that the statement touches.
+// The name of the table is qualified with the database if it has one, e.g. db.table.
+// The statements on a trigger touch the table that the trigger is associated with.
+func tableName(stmt diff.Stmt) string {
+	target := stmt.After()
+	if target == nil || stmt.Kind() == diff.StmtKindRenameTable {
+		target = stmt.Before()
+	}
+	switch v := target.(type) {
+	case *model.Table:
+		if v.Schema != "" {
+			return string(v.Schema) + "." + string(v.Name)
+		}
+		return string(v.Name)
+	case *model.View:
+		return string(v.Name)
+	case *model.Routine:
+		return string(v.Name)
+	case *model.Trigger:
+		return string(v.Table)
+	case *model.Event:
+		return string(v.Name)
+	case *model.Database:
+		return string(v.Name)
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package deploy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlan_MarshalJSON(t *testing.T) {
+	const from = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `c` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	const to = "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );"
+	plan := newTestPlan(t, from, to)
+	plan.Revision = 42
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"revision":    42.0,
+		"from":        from,
+		"to":          to,
+		"impact":      "data-loss",
+		"destructive": 1.0,
+		"statements": []any{
+			map[string]any{
+				"sql":    "ALTER TABLE `hoge` DROP COLUMN `c`",
+				"kind":   "alter-table",
+				"table":  "hoge",
+				"impact": "data-loss",
+				"clauses": []any{
+					map[string]any{
+						"sql":    "DROP COLUMN `c`",
+						"kind":   "drop-column",
+						"impact": "data-loss",
+					},
+				},
+				"warnings": []any{"the statement may lose data"},
+			},
+		},
+		"rollback": []any{
+			map[string]any{
+				"sql":    "ALTER TABLE `hoge` ADD COLUMN `c` INT (11) NOT NULL AFTER `id`",
+				"kind":   "alter-table",
+				"table":  "hoge",
+				"impact": "safe",
+				"clauses": []any{
+					map[string]any{
+						"sql":    "ADD COLUMN `c` INT (11) NOT NULL AFTER `id`",
+						"kind":   "add-column",
+						"impact": "safe",
+					},
+				},
+				"warnings": []any{"the statement cannot restore the data lost by the deploy"},
+			},
+		},
+		"warnings": []any{
+			"the plan contains statements that may lose data",
+			"the rollback plan cannot restore the data lost by the deploy",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("json mismatch (-want/+got):\n%s", diff)
+	}
+}
+
+func TestPlan_MarshalJSON_Empty(t *testing.T) {
+	plan := &Plan{}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"revision":0,"from":"","to":"","impact":"safe","destructive":0,"statements":[],"rollback":[],"warnings":[]}`
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Errorf("json mismatch (-want/+got):\n%s", diff)
+	}
+}
+
+func TestPlan_MarshalJSON_Table(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []string
+	}{
+		{
+			name: "qualified table",
+			from: "CREATE TABLE `other`.`hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );",
+			to:   "CREATE TABLE `other`.`hoge` ( `id` INTEGER NOT NULL, `c` INTEGER NOT NULL, PRIMARY KEY (`id`) );",
+			want: []string{"other.hoge"},
+		},
+		{
+			name: "trigger",
+			from: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );",
+			to: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) );\n" +
+				"CREATE TRIGGER `fuga` BEFORE INSERT ON `hoge` FOR EACH ROW SET NEW.id = 1;",
+			want: []string{"hoge"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := newTestPlan(t, tt.from, tt.to)
+			data, err := json.Marshal(plan)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var v struct {
+				Stmts []struct {
+					Table string `json:"table"`
+				} `json:"statements"`
+			}
+			if err := json.Unmarshal(data, &v); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, stmt := range v.Stmts {
+				got = append(got, stmt.Table)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("table mismatch (-want/+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,492 @@
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=Strategy -linecomment -output=strategy_string_gen.go
+
+package deploy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/shogo82148/schemalex-deploy/diff"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// Strategy describes how ALTER TABLE statements are executed.
+type Strategy int
+
+// List of possible Strategy values.
+const (
+	// StrategyDirect executes ALTER TABLE statements as they are.
+	StrategyDirect Strategy = iota // direct
+
+	// StrategyOnline appends ALGORITHM=INSTANT or ALGORITHM=INPLACE, LOCK=NONE to ALTER TABLE statements,
+	// and falls back to the plain statements if MySQL doesn't support them.
+	StrategyOnline // online
+
+	// StrategyCopy migrates the tables by copying them to shadow tables if ALTER TABLE statements may lock them for a long time.
+	// It creates a shadow table, applies ALTER TABLE to it, copies rows by primary key chunks,
+	// catches up the changes with triggers, and swaps the tables by RENAME TABLE.
+	// It falls back to StrategyOnline if the table can't be copied,
+	// e.g. the table has no primary key, or it has foreign keys.
+	StrategyCopy // copy
+)
+
+// ParseStrategy parses the name of the strategy.
+func ParseStrategy(s string) (Strategy, error) {
+	for _, strategy := range []Strategy{StrategyDirect, StrategyOnline, StrategyCopy} {
+		if strings.EqualFold(s, strategy.String()) {
+			return strategy, nil
+		}
+	}
+	return StrategyDirect, fmt.Errorf("unknown strategy: %q", s)
+}
+
+const defaultChunkSize = 1000
+
+// instantHint is the hint for ALGORITHM=INSTANT.
+// MySQL 8.0.12 and later, and MariaDB 10.3.2 and later support it.
+const instantHint = "ALGORITHM=INSTANT"
+
+// onlineHints are the hints appended to ALTER TABLE statements, in order of preference.
+var onlineHints = []string{
+	instantHint,
+	"ALGORITHM=INPLACE, LOCK=NONE",
+}
+
+// execStmt executes the statement according to the strategy.
+func execStmt(ctx context.Context, tx *sql.Tx, stmt diff.Stmt, opts *myOptions) error {
+	if stmt.Kind() != diff.StmtKindAlterTable || isPartitionStmt(stmt) {
+		// the partition operations can't be combined with the online hints.
+		return execQuery(ctx, tx, stmt.String())
+	}
+
+	switch opts.strategy {
+	case StrategyOnline:
+		return execOnline(ctx, tx, stmt.String(), opts.version)
+	case StrategyCopy:
+		if stmt.Impact() != diff.ImpactLockHeavy {
+			// the statement doesn't rebuild the table, or it may lose data.
+			// we don't copy the rows silently in the later case.
+			return execOnline(ctx, tx, stmt.String(), opts.version)
+		}
+		c, err := newShadowCopy(stmt)
+		if err == nil {
+			err = c.checkForeignKeys(ctx, tx)
+		}
+		if err == nil {
+			err = c.checkTriggers(ctx, tx)
+		}
+		if err != nil {
+			log.Printf("cannot copy the table, falling back to online ALTER TABLE: %v", err)
+			return execOnline(ctx, tx, stmt.String(), opts.version)
+		}
+		return c.run(ctx, tx, opts.chunkSize)
+	}
+	return execQuery(ctx, tx, stmt.String())
+}
+
+// isPartitionStmt returns whether the statement changes the partitioning of the table.
+func isPartitionStmt(stmt diff.Stmt) bool {
+	for _, c := range stmt.Clauses() {
+		switch c.Kind() {
+		case diff.ClauseKindPartitionBy, diff.ClauseKindRemovePartitioning, diff.ClauseKindAddPartition,
+			diff.ClauseKindDropPartition, diff.ClauseKindReorganizePartition, diff.ClauseKindCoalescePartition:
+			return true
+		}
+	}
+	return false
+}
+
+func execQuery(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) error {
+	log.Printf("executing: %s", query)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute %q: %w", query, err)
+	}
+	return nil
+}
+
+// execOnline executes the ALTER TABLE statement with the online hints.
+// version is the version of the server, and it may be nil.
+func execOnline(ctx context.Context, tx *sql.Tx, query string, version *model.ServerVersion) error {
+	for _, hint := range onlineHints {
+		if hint == instantHint && version != nil && !version.SupportsInstantAlter() {
+			continue
+		}
+		q := query + ", " + hint
+		log.Printf("executing: %s", q)
+		_, err := tx.ExecContext(ctx, q)
+		if err == nil {
+			return nil
+		}
+		if !isAlterNotSupported(err) {
+			return fmt.Errorf("failed to execute %q: %w", q, err)
+		}
+		log.Printf("%s is not supported: %v", hint, err)
+	}
+	return execQuery(ctx, tx, query)
+}
+
+// isAlterNotSupported returns whether the error means MySQL doesn't support the algorithm or the lock level.
+func isAlterNotSupported(err error) bool {
+	var myerr *mysql.MySQLError
+	if !errors.As(err, &myerr) {
+		return false
+	}
+	// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+	switch myerr.Number {
+	case 1800, // ER_UNKNOWN_ALTER_ALGORITHM
+		1801, // ER_UNKNOWN_ALTER_LOCK
+		1845, // ER_ALTER_OPERATION_NOT_SUPPORTED
+		1846: // ER_ALTER_OPERATION_NOT_SUPPORTED_REASON
+		return true
+	}
+	return false
+}
+
+// shadowCopy migrates a table by copying it to a shadow table.
+type shadowCopy struct {
+	table  model.Ident // the name of the table
+	shadow model.Ident // the name of the shadow table
+	old    model.Ident // the name of the table after swapping
+
+	alter   string        // ALTER TABLE statement for the shadow table
+	columns []model.Ident // the columns in the shadow table to copy
+	sources []model.Ident // the columns in the table to copy from
+	keys    []model.Ident // the primary key of the table
+
+	triggers []trigger
+}
+
+type trigger struct {
+	name  model.Ident
+	event string // INSERT, UPDATE or DELETE
+	body  string
+}
+
+// maxIdentLength is the maximum length of the identifiers of tables and triggers.
+const maxIdentLength = 64
+
+// newShadowCopy plans to migrate the table by copying.
+// It returns an error if the table can't be copied.
+func newShadowCopy(stmt diff.Stmt) (*shadowCopy, error) {
+	before, ok := stmt.Before().(*model.Table)
+	if !ok {
+		return nil, errors.New("the table is not found")
+	}
+	after, ok := stmt.After().(*model.Table)
+	if !ok {
+		return nil, errors.New("the table is not found")
+	}
+
+	name := string(before.Name)
+	if before.Schema != "" {
+		// the shadow table and the triggers are created in the current database.
+		return nil, fmt.Errorf("the table %q is in another database %q", name, string(before.Schema))
+	}
+	if len(name)+len("__new") > maxIdentLength {
+		return nil, fmt.Errorf("the table name %q is too long", name)
+	}
+	c := &shadowCopy{
+		table:  before.Name,
+		shadow: model.Ident("_" + name + "_new"),
+		old:    model.Ident("_" + name + "_old"),
+	}
+
+	for _, t := range []*model.Table{before, after} {
+		for _, idx := range t.Indexes {
+			if idx.Kind == model.IndexKindForeignKey {
+				return nil, fmt.Errorf("the table %q has foreign keys", name)
+			}
+		}
+		if len(t.Checks) > 0 {
+			// CREATE TABLE ... LIKE renames the CHECK constraints.
+			return nil, fmt.Errorf("the table %q has CHECK constraints", name)
+		}
+	}
+
+	// the primary key must be kept, because the rows are copied by the primary key.
+	beforeKeys := primaryKey(before)
+	if len(beforeKeys) == 0 {
+		return nil, fmt.Errorf("the table %q has no primary key", name)
+	}
+	afterKeys := primaryKey(after)
+	if !equalIdents(beforeKeys, afterKeys) {
+		return nil, fmt.Errorf("the primary key of the table %q is changed", name)
+	}
+	c.keys = beforeKeys
+
+	// the columns to copy
+	for _, col := range after.Columns {
+		if col.Generated.Valid {
+			continue
+		}
+		src, ok := before.LookupColumn(col.ID())
+		if !ok && col.RenamedFrom.Valid {
+			src, ok = before.LookupColumn(model.NewTableColumn(string(col.RenamedFrom.Ident)).ID())
+		}
+		if !ok {
+			// the new column is filled with the default value.
+			continue
+		}
+		c.columns = append(c.columns, col.Name)
+		c.sources = append(c.sources, src.Name)
+	}
+
+	clauses := make([]string, 0, len(stmt.Clauses()))
+	for _, clause := range stmt.Clauses() {
+		clauses = append(clauses, clause.String())
+	}
+	c.alter = "ALTER TABLE " + c.shadow.Quoted() + " " + strings.Join(clauses, ", ")
+
+	// the triggers to catch up the changes during the copy.
+	replace := "REPLACE INTO " + c.shadow.Quoted() + " (" + joinIdents(c.columns, "", ", ") + ") " +
+		"VALUES (" + joinIdents(c.sources, "NEW.", ", ") + ")"
+	deleteOld := "DELETE IGNORE FROM " + c.shadow.Quoted() + " WHERE " + matchKeys(c.keys, "OLD.")
+	c.triggers = []trigger{
+		{
+			name:  model.Ident("_" + name + "_ins"),
+			event: "INSERT",
+			body:  replace,
+		},
+		{
+			name:  model.Ident("_" + name + "_upd"),
+			event: "UPDATE",
+			body:  "BEGIN " + deleteOld + "; " + replace + "; END",
+		},
+		{
+			name:  model.Ident("_" + name + "_del"),
+			event: "DELETE",
+			body:  deleteOld,
+		},
+	}
+	return c, nil
+}
+
+// checkForeignKeys returns an error if other tables refer to the table,
+// because RENAME TABLE breaks the references.
+func (c *shadowCopy) checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	var count int
+	row := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM `information_schema`.`KEY_COLUMN_USAGE` "+
+		"WHERE `REFERENCED_TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_NAME` = ?", string(c.table))
+	if err := row.Scan(&count); err != nil {
+		return fmt.Errorf("failed to get foreign keys: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("the table %q is referred by foreign keys", string(c.table))
+	}
+	return nil
+}
+
+// checkTriggers returns an error if the table has triggers,
+// because RENAME TABLE moves them to the old table, and they are dropped with it.
+func (c *shadowCopy) checkTriggers(ctx context.Context, tx *sql.Tx) error {
+	var count int
+	row := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM `information_schema`.`TRIGGERS` "+
+		"WHERE `EVENT_OBJECT_SCHEMA` = DATABASE() AND `EVENT_OBJECT_TABLE` = ?", string(c.table))
+	if err := row.Scan(&count); err != nil {
+		return fmt.Errorf("failed to get triggers: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("the table %q has triggers", string(c.table))
+	}
+	return nil
+}
+
+func (c *shadowCopy) createTriggers() []string {
+	ret := make([]string, 0, len(c.triggers))
+	for _, t := range c.triggers {
+		ret = append(ret, "CREATE TRIGGER "+t.name.Quoted()+" AFTER "+t.event+" ON "+c.table.Quoted()+" FOR EACH ROW "+t.body)
+	}
+	return ret
+}
+
+func (c *shadowCopy) dropTriggers() []string {
+	ret := make([]string, 0, len(c.triggers))
+	for _, t := range c.triggers {
+		ret = append(ret, "DROP TRIGGER IF EXISTS "+t.name.Quoted())
+	}
+	return ret
+}
+
+// nextChunk returns the query to get the last primary key of the next chunk.
+func (c *shadowCopy) nextChunk(first bool) string {
+	var buf strings.Builder
+	buf.WriteString("SELECT ")
+	buf.WriteString(joinIdents(c.keys, "", ", "))
+	buf.WriteString(" FROM ")
+	buf.WriteString(c.table.Quoted())
+	if !first {
+		buf.WriteString(" WHERE (")
+		buf.WriteString(joinIdents(c.keys, "", ", "))
+		buf.WriteString(") > (")
+		buf.WriteString(placeholders(len(c.keys)))
+		buf.WriteString(")")
+	}
+	buf.WriteString(" ORDER BY ")
+	buf.WriteString(joinIdents(c.keys, "", ", "))
+	buf.WriteString(" LIMIT 1 OFFSET ?")
+	return buf.String()
+}
+
+// copyChunk returns the query to copy the rows in the chunk.
+// the chunk is (lower, upper]. lower and upper are omitted if they are not specified.
+func (c *shadowCopy) copyChunk(lower, upper bool) string {
+	var buf strings.Builder
+	buf.WriteString("INSERT IGNORE INTO ")
+	buf.WriteString(c.shadow.Quoted())
+	buf.WriteString(" (")
+	buf.WriteString(joinIdents(c.columns, "", ", "))
+	buf.WriteString(") SELECT ")
+	buf.WriteString(joinIdents(c.sources, "", ", "))
+	buf.WriteString(" FROM ")
+	buf.WriteString(c.table.Quoted())
+
+	var conds []string
+	keys := joinIdents(c.keys, "", ", ")
+	if lower {
+		conds = append(conds, "("+keys+") > ("+placeholders(len(c.keys))+")")
+	}
+	if upper {
+		conds = append(conds, "("+keys+") <= ("+placeholders(len(c.keys))+")")
+	}
+	if len(conds) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(conds, " AND "))
+	}
+	buf.WriteString(" LOCK IN SHARE MODE")
+	return buf.String()
+}
+
+// swap returns the query to swap the table and the shadow table atomically.
+func (c *shadowCopy) swap() string {
+	return "RENAME TABLE " + c.table.Quoted() + " TO " + c.old.Quoted() + ", " + c.shadow.Quoted() + " TO " + c.table.Quoted()
+}
+
+func (c *shadowCopy) run(ctx context.Context, tx *sql.Tx, chunkSize int) (err error) {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	log.Printf("copying the table %s to %s", c.table.Quoted(), c.shadow.Quoted())
+
+	// clean up the shadow table if the migration fails.
+	defer func() {
+		if err == nil {
+			return
+		}
+		ctx := context.WithoutCancel(ctx)
+		for _, q := range c.dropTriggers() {
+			execQuery(ctx, tx, q)
+		}
+		execQuery(ctx, tx, "DROP TABLE IF EXISTS "+c.shadow.Quoted())
+	}()
+
+	if err := execQuery(ctx, tx, "CREATE TABLE "+c.shadow.Quoted()+" LIKE "+c.table.Quoted()); err != nil {
+		return err
+	}
+	if err := execQuery(ctx, tx, c.alter); err != nil {
+		return err
+	}
+	for _, q := range c.createTriggers() {
+		if err := execQuery(ctx, tx, q); err != nil {
+			return err
+		}
+	}
+	if err := c.copyRows(ctx, tx, chunkSize); err != nil {
+		return err
+	}
+	if err := execQuery(ctx, tx, c.swap()); err != nil {
+		return err
+	}
+
+	// the triggers are moved to the old table by RENAME TABLE.
+	for _, q := range c.dropTriggers() {
+		if err := execQuery(ctx, tx, q); err != nil {
+			return err
+		}
+	}
+	return execQuery(ctx, tx, "DROP TABLE "+c.old.Quoted())
+}
+
+// copyRows copies the rows by primary key chunks.
+func (c *shadowCopy) copyRows(ctx context.Context, tx *sql.Tx, chunkSize int) error {
+	var lower []interface{}
+	for {
+		// find the upper bound of the chunk
+		args := append(append([]interface{}{}, lower...), chunkSize-1)
+		upper := make([]interface{}, len(c.keys))
+		dest := make([]interface{}, len(c.keys))
+		for i := range dest {
+			dest[i] = &upper[i]
+		}
+		err := tx.QueryRowContext(ctx, c.nextChunk(lower == nil), args...).Scan(dest...)
+		if errors.Is(err, sql.ErrNoRows) {
+			// this is the last chunk.
+			return execQuery(ctx, tx, c.copyChunk(lower != nil, false), lower...)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get the next chunk: %w", err)
+		}
+
+		args = append(append([]interface{}{}, lower...), upper...)
+		if err := execQuery(ctx, tx, c.copyChunk(lower != nil, true), args...); err != nil {
+			return err
+		}
+		lower = upper
+	}
+}
+
+// primaryKey returns the columns of the primary key.
+func primaryKey(table *model.Table) []model.Ident {
+	for _, idx := range table.Indexes {
+		if idx.Kind != model.IndexKindPrimaryKey {
+			continue
+		}
+		keys := make([]model.Ident, 0, len(idx.Columns))
+		for _, col := range idx.Columns {
+			keys = append(keys, col.Name)
+		}
+		return keys
+	}
+	return nil
+}
+
+func equalIdents(a, b []model.Ident) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(string(a[i]), string(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func joinIdents(idents []model.Ident, prefix, sep string) string {
+	var buf strings.Builder
+	for i, ident := range idents {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		buf.WriteString(prefix)
+		buf.WriteString(ident.Quoted())
+	}
+	return buf.String()
+}
+
+// matchKeys returns the condition that matches the primary key of the row.
+func matchKeys(keys []model.Ident, prefix string) string {
+	conds := make([]string, 0, len(keys))
+	for _, key := range keys {
+		conds = append(conds, key.Quoted()+" <=> "+prefix+key.Quoted())
+	}
+	return strings.Join(conds, " AND ")
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
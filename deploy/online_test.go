@@ -0,0 +1,228 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/diff"
+	"github.com/shogo82148/schemalex-deploy/internal/database"
+)
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Strategy
+	}{
+		{"direct", StrategyDirect},
+		{"online", StrategyOnline},
+		{"COPY", StrategyCopy},
+	}
+	for _, tt := range tests {
+		got, err := ParseStrategy(tt.in)
+		if err != nil {
+			t.Errorf("ParseStrategy(%q) returns error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseStrategy(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseStrategy("unknown"); err == nil {
+		t.Error("want error, but not")
+	}
+}
+
+func planAlterTable(t *testing.T, before, after string) diff.Stmt {
+	t.Helper()
+	p := schemalex.New()
+	stmts1, err := p.ParseString(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts2, err := p.ParseString(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := diff.Diff(stmts1, stmts2, diff.WithTransaction(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 1 || stmts[0].Kind() != diff.StmtKindAlterTable {
+		t.Fatalf("want one ALTER TABLE statement, got %v", stmts)
+	}
+	return stmts[0]
+}
+
+func TestShadowCopy(t *testing.T) {
+	stmt := planAlterTable(t,
+		"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` VARCHAR (20) NOT NULL, PRIMARY KEY (`id`) )",
+		"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` TEXT NOT NULL, `c` INTEGER NOT NULL, PRIMARY KEY (`id`) )",
+	)
+	c, err := newShadowCopy(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := "ALTER TABLE `_hoge_new` ADD COLUMN `c` INT (11) NOT NULL AFTER `b`, CHANGE COLUMN `a` `b` TEXT NOT NULL", c.alter; want != got {
+		t.Errorf("unexpected alter: want %q, got %q", want, got)
+	}
+
+	wantTriggers := []string{
+		"CREATE TRIGGER `_hoge_ins` AFTER INSERT ON `hoge` FOR EACH ROW " +
+			"REPLACE INTO `_hoge_new` (`id`, `b`) VALUES (NEW.`id`, NEW.`a`)",
+		"CREATE TRIGGER `_hoge_upd` AFTER UPDATE ON `hoge` FOR EACH ROW " +
+			"BEGIN DELETE IGNORE FROM `_hoge_new` WHERE `id` <=> OLD.`id`; " +
+			"REPLACE INTO `_hoge_new` (`id`, `b`) VALUES (NEW.`id`, NEW.`a`); END",
+		"CREATE TRIGGER `_hoge_del` AFTER DELETE ON `hoge` FOR EACH ROW " +
+			"DELETE IGNORE FROM `_hoge_new` WHERE `id` <=> OLD.`id`",
+	}
+	if diff := cmp.Diff(wantTriggers, c.createTriggers()); diff != "" {
+		t.Errorf("triggers mismatch (-want/+got):\n%s", diff)
+	}
+
+	if want, got := "SELECT `id` FROM `hoge` WHERE (`id`) > (?) ORDER BY `id` LIMIT 1 OFFSET ?", c.nextChunk(false); want != got {
+		t.Errorf("unexpected query: want %q, got %q", want, got)
+	}
+	if want, got := "INSERT IGNORE INTO `_hoge_new` (`id`, `b`) SELECT `id`, `a` FROM `hoge` WHERE (`id`) > (?) AND (`id`) <= (?) LOCK IN SHARE MODE", c.copyChunk(true, true); want != got {
+		t.Errorf("unexpected query: want %q, got %q", want, got)
+	}
+	if want, got := "RENAME TABLE `hoge` TO `_hoge_old`, `_hoge_new` TO `hoge`", c.swap(); want != got {
+		t.Errorf("unexpected query: want %q, got %q", want, got)
+	}
+}
+
+func TestShadowCopy_Unsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{
+			name:   "no primary key",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` BIGINT NOT NULL )",
+		},
+		{
+			name:   "change primary key",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, PRIMARY KEY (`id`) )",
+			after:  "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, PRIMARY KEY (`id`, `a`) )",
+		},
+		{
+			name:   "foreign key",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, PRIMARY KEY (`id`), CONSTRAINT `fk` FOREIGN KEY (`a`) REFERENCES `fuga` (`id`) )",
+			after:  "CREATE TABLE `hoge` ( `id` BIGINT NOT NULL, `a` INTEGER NOT NULL, PRIMARY KEY (`id`), CONSTRAINT `fk` FOREIGN KEY (`a`) REFERENCES `fuga` (`id`) )",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := planAlterTable(t, tt.before, tt.after)
+			if _, err := newShadowCopy(stmt); err == nil {
+				t.Error("want error, but not")
+			}
+		})
+	}
+}
+
+func TestDeployWithStrategy(t *testing.T) {
+	database.SkipIfNoTestDatabase(t)
+
+	for _, strategy := range []Strategy{StrategyOnline, StrategyCopy} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			rawDB, cleanup := database.SetupTestDB()
+			defer cleanup()
+			db := &DB{
+				db: rawDB,
+			}
+
+			const schema1 = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, a VARCHAR(20) NOT NULL, PRIMARY KEY (id) );"
+			plan, err := db.Plan(ctx, schema1)
+			if err != nil {
+				t.Fatalf("failed to plan: %v", err)
+			}
+			if err := db.Deploy(ctx, plan); err != nil {
+				t.Fatalf("failed to deploy: %v", err)
+			}
+			for i := 0; i < 10; i++ {
+				if _, err := db.db.ExecContext(ctx, "INSERT INTO hoge (a) VALUES ('foo')"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// widening the column rebuilds the table.
+			const schema2 = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, a VARCHAR(255) NOT NULL, PRIMARY KEY (id) );"
+			plan, err = db.Plan(ctx, schema2)
+			if err != nil {
+				t.Fatalf("failed to plan: %v", err)
+			}
+			if err := db.Deploy(ctx, plan, WithStrategy(strategy), WithChunkSize(3)); err != nil {
+				t.Fatalf("failed to deploy: %v", err)
+			}
+
+			hoge, err := showColumns(ctx, db.db, "hoge")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hoge) != 2 || hoge[1].Type != "varchar(255)" {
+				t.Errorf("unexpected columns: %v", hoge)
+			}
+
+			var count int
+			if err := db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM hoge WHERE a = 'foo'").Scan(&count); err != nil {
+				t.Fatal(err)
+			}
+			if count != 10 {
+				t.Errorf("want 10 rows, got %d", count)
+			}
+		})
+	}
+}
+
+func TestDeployWithStrategy_Triggers(t *testing.T) {
+	database.SkipIfNoTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rawDB, cleanup := database.SetupTestDB()
+	defer cleanup()
+	db := &DB{
+		db: rawDB,
+	}
+
+	const schema1 = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, a VARCHAR(20) NOT NULL, PRIMARY KEY (id) );\n" +
+		"CREATE TABLE log ( id INTEGER NOT NULL );\n" +
+		"CREATE TRIGGER hoge_ai AFTER INSERT ON hoge FOR EACH ROW INSERT INTO log (id) VALUES (NEW.id);"
+	plan, err := db.Plan(ctx, schema1)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if err := db.Deploy(ctx, plan); err != nil {
+		t.Fatalf("failed to deploy: %v", err)
+	}
+
+	// widening the column rebuilds the table, but the table with triggers is not copied.
+	const schema2 = "CREATE TABLE hoge ( id INTEGER NOT NULL AUTO_INCREMENT, a VARCHAR(255) NOT NULL, PRIMARY KEY (id) );\n" +
+		"CREATE TABLE log ( id INTEGER NOT NULL );\n" +
+		"CREATE TRIGGER hoge_ai AFTER INSERT ON hoge FOR EACH ROW INSERT INTO log (id) VALUES (NEW.id);"
+	plan, err = db.Plan(ctx, schema2)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if err := db.Deploy(ctx, plan, WithStrategy(StrategyCopy)); err != nil {
+		t.Fatalf("failed to deploy: %v", err)
+	}
+
+	var count int
+	row := db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM `information_schema`.`TRIGGERS` "+
+		"WHERE `EVENT_OBJECT_SCHEMA` = DATABASE() AND `TRIGGER_NAME` = 'hoge_ai'")
+	if err := row.Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("want the trigger hoge_ai is kept, but not")
+	}
+}
@@ -0,0 +1,68 @@
+package deploy
+
+import "github.com/shogo82148/schemalex-deploy/model"
+
+type myOptions struct {
+	source    Source
+	strategy  Strategy
+	chunkSize int
+	version   *model.ServerVersion
+}
+
+// Option is an option for NewPlan, DB.Plan, DB.RollbackPlan and DB.Deploy.
+type Option interface {
+	apply(opts *myOptions)
+}
+
+type withSource Source
+
+func (opt withSource) apply(opts *myOptions) {
+	opts.source = Source(opt)
+}
+
+// WithSource specifies the schema that DB.Plan migrates from.
+// The default is SourceRevision.
+func WithSource(s Source) Option {
+	return withSource(s)
+}
+
+type withStrategy Strategy
+
+func (opt withStrategy) apply(opts *myOptions) {
+	opts.strategy = Strategy(opt)
+}
+
+// WithStrategy specifies how ALTER TABLE statements are executed.
+// The default is StrategyDirect.
+func WithStrategy(s Strategy) Option {
+	return withStrategy(s)
+}
+
+type withChunkSize int
+
+func (opt withChunkSize) apply(opts *myOptions) {
+	opts.chunkSize = int(opt)
+}
+
+// WithChunkSize specifies the number of rows copied at once by StrategyCopy.
+// The default is 1000.
+func WithChunkSize(n int) Option {
+	if n <= 0 {
+		n = defaultChunkSize
+	}
+	return withChunkSize(n)
+}
+
+type withServerVersion model.ServerVersion
+
+func (opt withServerVersion) apply(opts *myOptions) {
+	v := model.ServerVersion(opt)
+	opts.version = &v
+}
+
+// WithServerVersion specifies the version of the server,
+// and the plan uses the syntax appropriate for it.
+// The default is the version detected by Open.
+func WithServerVersion(v model.ServerVersion) Option {
+	return withServerVersion(v)
+}
@@ -0,0 +1,31 @@
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=Source -linecomment -output=source_string_gen.go
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Source describes the schema that DB.Plan migrates from.
+type Source int
+
+// List of possible Source values.
+const (
+	// SourceRevision plans from the latest revision recorded in schemalex_revision.
+	SourceRevision Source = iota // revision
+
+	// SourceLive plans from the live schema loaded by SHOW CREATE TABLE.
+	// It is useful for the databases that are not managed by schemalex-deploy yet.
+	SourceLive // live
+)
+
+// ParseSource parses the name of the source.
+func ParseSource(s string) (Source, error) {
+	for _, source := range []Source{SourceRevision, SourceLive} {
+		if strings.EqualFold(s, source.String()) {
+			return source, nil
+		}
+	}
+	return SourceRevision, fmt.Errorf("unknown source: %q", s)
+}
@@ -0,0 +1,71 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/format"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// dropTableChecks drops the CHECK constraints that are removed or whose expressions are changed.
+// They are dropped before the columns, because MySQL can't drop the columns used in CHECK constraints.
+func (ctx *alterCtx) dropTableChecks() error {
+	for _, check := range ctx.from.Checks {
+		after, ok := ctx.to.LookupCheck(check.ID())
+		if ok && equalExpr(check.Expr, after.Expr) {
+			continue
+		}
+		ctx.begin(ClauseKindDropCheck, check, nil)
+		ctx.writeString("DROP CHECK ")
+		ctx.writeIdent(check.Name.Ident)
+	}
+	return nil
+}
+
+// addTableChecks adds the new CHECK constraints, and changes the enforcement of the existing ones.
+func (ctx *alterCtx) addTableChecks() error {
+	for _, check := range ctx.to.Checks {
+		before, ok := ctx.from.LookupCheck(check.ID())
+		if ok && equalExpr(before.Expr, check.Expr) {
+			if before.NotEnforced == check.NotEnforced {
+				continue
+			}
+			ctx.begin(ClauseKindAlterCheck, before, check)
+			ctx.writeString("ALTER CHECK ")
+			ctx.writeIdent(check.Name.Ident)
+			if check.NotEnforced {
+				ctx.writeString(" NOT ENFORCED")
+			} else {
+				// all rows are validated against the constraint.
+				ctx.raise(ImpactLockHeavy)
+				ctx.writeString(" ENFORCED")
+			}
+			continue
+		}
+
+		ctx.begin(ClauseKindAddCheck, nil, check)
+		if !check.NotEnforced {
+			// all rows are validated against the constraint.
+			ctx.raise(ImpactLockHeavy)
+		}
+		ctx.writeString("ADD ")
+		if err := format.SQL(&ctx.buf, check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameChecks renames the CHECK constraints named by MySQL automatically,
+// because MySQL renames them when the table is renamed.
+// e.g. `old_chk_1` is renamed to `new_chk_1`.
+func renameChecks(table *model.Table, oldName model.Ident) {
+	prefix := strings.ToLower(string(oldName)) + "_chk_"
+	for _, check := range table.Checks {
+		name := string(check.Name.Ident)
+		if !strings.HasPrefix(strings.ToLower(name), prefix) {
+			continue
+		}
+		check.Name.Ident = model.Ident(string(table.Name) + "_chk_" + name[len(prefix):])
+	}
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// alterDatabases changes the default options of the databases.
+// The new tables inherit the default character set and collation, so the databases are altered first.
+// The databases are neither created nor dropped; they are the targets of deploying.
+func (ctx *diffCtx) alterDatabases() error {
+	for _, stmt := range ctx.to {
+		after, ok := stmt.(*model.Database)
+		if !ok {
+			continue
+		}
+
+		// compare with the database deployed to MySQL actually, if it is known.
+		var before *model.Database
+		if stmt, ok := ctx.cur.Lookup(after.ID()); ok {
+			before, _ = stmt.(*model.Database)
+		}
+		if before == nil {
+			if stmt, ok := ctx.from.Lookup(after.ID()); ok {
+				before, _ = stmt.(*model.Database)
+			}
+		}
+		if before == nil {
+			continue
+		}
+
+		if sql, ok := ctx.alterDatabase(before, after); ok {
+			ctx.append(Stmt{
+				sql:    sql,
+				kind:   StmtKindAlterDatabase,
+				table:  after.ID(),
+				before: before,
+				after:  after,
+			})
+		}
+	}
+	return nil
+}
+
+// alterDatabase returns the ALTER DATABASE statement that changes the database from before to after.
+// Only the options specified in after are compared.
+// It returns false if nothing is changed.
+func (ctx *diffCtx) alterDatabase(before, after *model.Database) (string, bool) {
+	b, a := before.Canonical(ctx.version), after.Canonical(ctx.version)
+
+	var buf strings.Builder
+	var changed bool
+	buf.WriteString("ALTER DATABASE ")
+	buf.WriteString(after.Name.Quoted())
+
+	// changing the character set also resets the collation, so they are changed together.
+	if (a.CharacterSet.Valid || a.Collation.Valid) && (b.CharacterSet != a.CharacterSet || b.Collation != a.Collation) {
+		if a.CharacterSet.Valid {
+			buf.WriteString(" CHARACTER SET = ")
+			buf.WriteString(string(a.CharacterSet.Ident))
+		}
+		if after.Collation.Valid {
+			buf.WriteString(" COLLATE = ")
+			buf.WriteString(string(after.Collation.Ident))
+		}
+		changed = true
+	}
+	if a.Encryption.Valid && viewOrDefault(b.Encryption.Value, "N") != a.Encryption.Value {
+		buf.WriteString(" ENCRYPTION = '")
+		buf.WriteString(a.Encryption.Value)
+		buf.WriteByte('\'')
+		changed = true
+	}
+	return buf.String(), changed
+}
@@ -23,20 +23,27 @@ type diffCtx struct {
 	cur     model.Stmts
 	result  Stmts
 	indent  string
+	version model.ServerVersion
+
+	// target is the version of the server that runs the statements.
+	// if it is nil, the statements are compatible with all versions.
+	target *model.ServerVersion
+
+	// renames is the list of renamed tables.
+	// from and cur are already rewritten with the new names.
+	renames []*tableRename
 }
 
 func newDiffCtx(from, to, cur model.Stmts) *diffCtx {
+	// the sets contain the IDs of all kinds of statements.
+	// each proc picks up the statements that it handles.
 	fromSet := newSet()
 	for _, stmt := range from {
-		if cs, ok := stmt.(*model.Table); ok {
-			fromSet.Add(cs.ID())
-		}
+		fromSet.Add(stmt.ID())
 	}
 	toSet := newSet()
 	for _, stmt := range to {
-		if cs, ok := stmt.(*model.Table); ok {
-			toSet.Add(cs.ID())
-		}
+		toSet.Add(stmt.ID())
 	}
 
 	return &diffCtx{
@@ -48,8 +55,8 @@ func newDiffCtx(from, to, cur model.Stmts) *diffCtx {
 	}
 }
 
-func (ctx *diffCtx) append(stmt string) {
-	ctx.result.Append(Stmt(stmt))
+func (ctx *diffCtx) append(stmt Stmt) {
+	ctx.result.Append(stmt)
 }
 
 // Diff compares two model.Stmts, and generates a series of
@@ -75,18 +82,40 @@ func Diff(from, to model.Stmts, options ...Option) (Stmts, error) {
 			return nil, err
 		}
 	}
-	ctx := newDiffCtx(from, to, cur)
+	if opts.database != "" {
+		from = unqualifyTables(from, opts.database)
+		to = unqualifyTables(to, opts.database)
+		cur = unqualifyTables(cur, opts.database)
+	}
+	renames := findTableRenames(from, to)
+	ctx := newDiffCtx(applyTableRenames(from, renames), to, applyTableRenames(cur, renames))
 	ctx.indent = opts.indent
+	ctx.renames = renames
+	ctx.version = model.DefaultServerVersion
+	if opts.version != nil {
+		ctx.version = *opts.version
+		ctx.target = opts.version
+	}
 
 	if txn {
-		ctx.append(`BEGIN`)
-		ctx.append(`SET FOREIGN_KEY_CHECKS = 0`)
+		ctx.append(Stmt{sql: `BEGIN`})
+		ctx.append(Stmt{sql: `SET FOREIGN_KEY_CHECKS = 0`})
 	}
 
 	procs := []func() error{
+		ctx.alterDatabases,
+		ctx.dropTriggers,
+		ctx.dropEvents,
+		ctx.dropViews,
+		ctx.dropRoutines,
 		ctx.dropTables,
+		ctx.renameTables,
 		ctx.createTables,
 		ctx.alterTables,
+		ctx.createRoutines,
+		ctx.createViews,
+		ctx.createTriggers,
+		ctx.createEvents,
 	}
 	for _, p := range procs {
 		if err := p(); err != nil {
@@ -95,13 +124,52 @@ func Diff(from, to model.Stmts, options ...Option) (Stmts, error) {
 	}
 
 	if txn {
-		ctx.append(`SET FOREIGN_KEY_CHECKS = 1`)
-		ctx.append(`COMMIT`)
+		ctx.append(Stmt{sql: `SET FOREIGN_KEY_CHECKS = 1`})
+		ctx.append(Stmt{sql: `COMMIT`})
 	}
 
 	return ctx.result, nil
 }
 
+// unqualifyTables returns a copy of stmts that the database name of the tables in the database is removed.
+func unqualifyTables(stmts model.Stmts, database string) model.Stmts {
+	ret := make(model.Stmts, 0, len(stmts))
+	for _, stmt := range stmts {
+		table, ok := stmt.(*model.Table)
+		if !ok || !hasSchema(table, database) {
+			ret = append(ret, stmt)
+			continue
+		}
+		table = cloneTable(table)
+		if strings.EqualFold(string(table.Schema), database) {
+			table.Schema = ""
+			for _, idx := range table.Indexes {
+				idx.Table = table.ID()
+			}
+		}
+		for _, idx := range table.Indexes {
+			if idx.Reference != nil && strings.EqualFold(string(idx.Reference.TableSchema), database) {
+				idx.Reference.TableSchema = ""
+			}
+		}
+		ret = append(ret, table)
+	}
+	return ret
+}
+
+// hasSchema returns whether the table or its references are qualified with the database name.
+func hasSchema(table *model.Table, database string) bool {
+	if strings.EqualFold(string(table.Schema), database) {
+		return true
+	}
+	for _, idx := range table.Indexes {
+		if idx.Reference != nil && strings.EqualFold(string(idx.Reference.TableSchema), database) {
+			return true
+		}
+	}
+	return false
+}
+
 // Statements compares two model.Stmts and generates a series
 // of statements to migrate from the old one to the new one,
 // writing the result to `dst`
@@ -150,9 +218,28 @@ func (ctx *diffCtx) dropTables() error {
 
 		table, ok := stmt.(*model.Table)
 		if !ok {
-			return fmt.Errorf(`lookup failed: %q is not a model.Table`, id)
+			continue
 		}
-		ctx.append("DROP TABLE " + table.Name.Quoted())
+		ctx.append(Stmt{
+			sql:    "DROP TABLE " + table.QuotedName(),
+			impact: ImpactDataLoss,
+			kind:   StmtKindDropTable,
+			table:  id,
+			before: table,
+		})
+	}
+	return nil
+}
+
+func (ctx *diffCtx) renameTables() error {
+	for _, r := range ctx.renames {
+		ctx.append(Stmt{
+			sql:    "RENAME TABLE " + r.from.QuotedName() + " TO " + r.to.QuotedName(),
+			kind:   StmtKindRenameTable,
+			table:  r.from.ID(),
+			before: r.from,
+			after:  r.to,
+		})
 	}
 	return nil
 }
@@ -167,12 +254,20 @@ func (ctx *diffCtx) createTables() error {
 		if !ok {
 			return fmt.Errorf("failed to lookup table: %q", id)
 		}
+		if _, ok := stmt.(*model.Table); !ok {
+			continue
+		}
 
 		buf.Reset()
-		if err := format.SQL(&buf, stmt, format.WithIndent(ctx.indent, 1)); err != nil {
+		if err := format.SQL(&buf, stmt, formatOptions(ctx.target, format.WithIndent(ctx.indent, 1))...); err != nil {
 			return fmt.Errorf("failed to format a statement: %w", err)
 		}
-		ctx.append(buf.String())
+		ctx.append(Stmt{
+			sql:   buf.String(),
+			kind:  StmtKindCreateTable,
+			table: id,
+			after: stmt,
+		})
 	}
 	return nil
 }
@@ -186,18 +281,61 @@ type alterCtx struct {
 	to          *model.Table
 	buf         strings.Builder
 
+	// original is the table before the column renames are applied.
+	original *model.Table
+
+	// renamedColumns maps the ID of a renamed column to its old name.
+	// from and cur are already rewritten with the new names.
+	renamedColumns map[string]model.Ident
+
+	// recreatedColumns are the columns that are dropped and added again,
+	// because they can't be changed by CHANGE COLUMN.
+	recreatedColumns set
+
+	// clauses are the alter specifications written in buf.
+	// clauseStart is the offset of the last clause in buf.
+	clauses     []Clause
+	clauseStart int
+
 	// cur is the current model deployed to MySQL actually.
 	// it may be nil.
 	cur *model.Table
+
+	// version is the version of the server.
+	// target is the version of the server that runs the statements, and it may be nil.
+	version model.ServerVersion
+	target  *model.ServerVersion
+
+	// renamedIndexes are the indexes that are renamed by RENAME INDEX.
+	renamedIndexes []indexRename
+}
+
+// indexRename describes an index that has the same definition but a different name.
+type indexRename struct {
+	from *model.Index
+	to   *model.Index
+}
+
+// formatOptions returns the options of format.SQL for the target server.
+func formatOptions(target *model.ServerVersion, options ...format.Option) []format.Option {
+	if target != nil {
+		options = append(options, format.WithServerVersion(*target))
+	}
+	return options
 }
 
 func (ctx *diffCtx) alterTables() error {
 	procs := []func(*alterCtx) error{
+		(*alterCtx).dropTableChecks,
 		(*alterCtx).dropTableIndexes,
+		(*alterCtx).renameTableIndexes,
 		(*alterCtx).dropTableColumns,
 		(*alterCtx).addTableColumns,
 		(*alterCtx).alterTableColumns,
 		(*alterCtx).addTableIndexes,
+		(*alterCtx).alterTableIndexes,
+		(*alterCtx).addTableChecks,
+		(*alterCtx).alterTableOptions,
 	}
 
 	ids := ctx.toSet.Intersect(ctx.fromSet)
@@ -210,7 +348,10 @@ func (ctx *diffCtx) alterTables() error {
 		if !ok {
 			return fmt.Errorf("table not found in old schema (alter table): %q", id)
 		}
-		beforeStmt := stmt.(*model.Table)
+		beforeStmt, ok := stmt.(*model.Table)
+		if !ok {
+			continue
+		}
 
 		// after statement
 		stmt, ok = ctx.to.Lookup(id)
@@ -229,13 +370,23 @@ func (ctx *diffCtx) alterTables() error {
 		}
 
 		alterCtx := newAlterCtx(ctx, beforeStmt, afterStmt, curStmt)
+		if stmt, ok := alterCtx.removePartitioning(); ok {
+			ctx.append(stmt)
+		}
 		for _, p := range procs {
 			if err := p(alterCtx); err != nil {
 				return fmt.Errorf("failed to generate alter table %q: %w", id, err)
 			}
 		}
 		if alterCtx.buf.Len() > 0 {
-			ctx.result = append(ctx.result, Stmt(alterCtx.buf.String()))
+			ctx.append(alterCtx.stmt())
+		}
+		stmts, err := alterCtx.alterPartitions()
+		if err != nil {
+			return fmt.Errorf("failed to generate alter table %q: %w", id, err)
+		}
+		for _, stmt := range stmts {
+			ctx.append(stmt)
 		}
 	}
 
@@ -243,6 +394,13 @@ func (ctx *diffCtx) alterTables() error {
 }
 
 func newAlterCtx(ctx *diffCtx, from, to, cur *model.Table) *alterCtx {
+	original := from
+	renamedColumns := findColumnRenames(from, to)
+	from = applyColumnRenames(from, to, renamedColumns)
+	if cur != nil {
+		cur = applyColumnRenames(cur, to, renamedColumns)
+	}
+
 	fromColumns := newSet()
 	for _, col := range from.Columns {
 		fromColumns.Add(col.ID())
@@ -253,6 +411,13 @@ func newAlterCtx(ctx *diffCtx, from, to, cur *model.Table) *alterCtx {
 		toColumns.Add(col.ID())
 	}
 
+	recreatedColumns := newSet()
+	for _, col := range to.Columns {
+		if before, ok := from.LookupColumn(col.ID()); ok && needsRecreateColumn(before, col) {
+			recreatedColumns.Add(col.ID())
+		}
+	}
+
 	fromIndexes := newSet()
 	for _, idx := range from.Indexes {
 		fromIndexes.Add(idx.ID())
@@ -262,6 +427,15 @@ func newAlterCtx(ctx *diffCtx, from, to, cur *model.Table) *alterCtx {
 	for _, idx := range to.Indexes {
 		toIndexes.Add(idx.ID())
 	}
+	matchAnonymousIndexes(from, to, fromIndexes, toIndexes)
+	matchAnonymousIndexes(to, from, toIndexes, fromIndexes)
+	keepForeignKeyIndexes(from, to, toIndexes)
+	ignoreRedundantIndexes(from, fromIndexes, toIndexes)
+	ignoreRedundantIndexes(to, fromIndexes, toIndexes)
+	var renamedIndexes []indexRename
+	if ctx.target != nil && ctx.target.SupportsRenameIndex() {
+		renamedIndexes = matchRenamedIndexes(from, to, fromIndexes, toIndexes)
+	}
 
 	return &alterCtx{
 		fromColumns: fromColumns,
@@ -271,18 +445,79 @@ func newAlterCtx(ctx *diffCtx, from, to, cur *model.Table) *alterCtx {
 		from:        from,
 		to:          to,
 		cur:         cur,
+		original:    original,
+		version:     ctx.version,
+		target:      ctx.target,
+
+		renamedColumns:   renamedColumns,
+		recreatedColumns: recreatedColumns,
+		renamedIndexes:   renamedIndexes,
 	}
 }
 
 // begin begins a new alter specification.
-func (ctx *alterCtx) begin() {
+// before and after are the column, the index or the table option that the specification changes.
+func (ctx *alterCtx) begin(kind ClauseKind, before, after model.Stmt) {
+	ctx.end()
 	if ctx.buf.Len() == 0 {
 		ctx.writeString("ALTER TABLE ")
-		ctx.writeIdent(ctx.from.Name)
+		ctx.writeString(ctx.from.QuotedName())
 		ctx.writeString(" ")
 	} else {
 		ctx.writeString(", ")
 	}
+	ctx.clauses = append(ctx.clauses, Clause{
+		kind:   kind,
+		before: before,
+		after:  after,
+	})
+	ctx.clauseStart = ctx.buf.Len()
+}
+
+// end ends the current alter specification.
+func (ctx *alterCtx) end() {
+	if len(ctx.clauses) == 0 {
+		return
+	}
+	ctx.clauses[len(ctx.clauses)-1].sql = ctx.buf.String()[ctx.clauseStart:]
+}
+
+// raise raises the impact of the current alter specification.
+func (ctx *alterCtx) raise(impact Impact) {
+	c := &ctx.clauses[len(ctx.clauses)-1]
+	if impact > c.impact {
+		c.impact = impact
+	}
+}
+
+// stmt returns the ALTER TABLE statement.
+func (ctx *alterCtx) stmt() Stmt {
+	ctx.end()
+	impact := ImpactSafe
+	for _, c := range ctx.clauses {
+		if c.impact > impact {
+			impact = c.impact
+		}
+	}
+	return Stmt{
+		sql:     ctx.buf.String(),
+		impact:  impact,
+		kind:    StmtKindAlterTable,
+		table:   ctx.to.ID(),
+		before:  ctx.original,
+		after:   ctx.to,
+		clauses: ctx.clauses,
+	}
+}
+
+// originalColumn returns the column in the original table.
+// id is the ID of the column after the column renames are applied.
+func (ctx *alterCtx) originalColumn(id string) *model.TableColumn {
+	if name, ok := ctx.renamedColumns[id]; ok {
+		id = model.NewTableColumn(string(name)).ID()
+	}
+	col, _ := ctx.original.LookupColumn(id)
+	return col
 }
 
 func (ctx *alterCtx) writeString(s string) {
@@ -294,16 +529,26 @@ func (ctx *alterCtx) writeIdent(ident model.Ident) {
 }
 
 func (ctx *alterCtx) dropTableColumns() error {
-	columnNames := ctx.fromColumns.Difference(ctx.toColumns)
+	columnNames := ctx.fromColumns.Difference(ctx.toColumns).Union(ctx.recreatedColumns)
 
 	for _, columnName := range columnNames.ToSlice() {
-		ctx.begin()
-		ctx.writeString("DROP COLUMN ")
 		col, ok := ctx.from.LookupColumn(columnName)
 		if !ok {
 			return fmt.Errorf("failed to lookup column %q", columnName)
 		}
-		ctx.writeIdent(col.Name)
+		name := col.Name
+		if oldName, ok := ctx.renamedColumns[columnName]; ok {
+			name = oldName
+		}
+		ctx.begin(ClauseKindDropColumn, ctx.originalColumn(columnName), nil)
+		impact := dropColumnImpact(col)
+		if after, ok := ctx.to.LookupColumn(columnName); ok && ctx.recreatedColumns.Contains(columnName) && !after.Generated.Valid {
+			// the generated values are lost, because the column added again has the default values.
+			impact = ImpactDataLoss
+		}
+		ctx.raise(impact)
+		ctx.writeString("DROP COLUMN ")
+		ctx.writeIdent(name)
 	}
 	return nil
 }
@@ -316,7 +561,7 @@ func (ctx *alterCtx) addTableColumns() error {
 	// we always start adding with a column that has a either no before
 	// columns, or one that already exists in the database
 	var firstColumn *model.TableColumn
-	for _, columnName := range ctx.toColumns.Difference(ctx.fromColumns).ToSlice() {
+	for _, columnName := range ctx.toColumns.Difference(ctx.fromColumns).Union(ctx.recreatedColumns).ToSlice() {
 		// find the before-column for each.
 		col, ok := ctx.to.LookupColumn(columnName)
 		if !ok {
@@ -344,7 +589,7 @@ func (ctx *alterCtx) addTableColumns() error {
 	var columnNames []string
 	// Find columns that have before columns which existed in both
 	// from and to tables
-	for _, columnName := range ctx.toColumns.Intersect(ctx.fromColumns).ToSlice() {
+	for _, columnName := range ctx.toColumns.Intersect(ctx.fromColumns).Difference(ctx.recreatedColumns).ToSlice() {
 		if nextColumnName, ok := beforeToNext[columnName]; ok {
 			delete(beforeToNext, columnName)
 			delete(nextToBefore, nextColumnName)
@@ -385,9 +630,10 @@ func (ctx *alterCtx) writeAddColumn(columnNames ...string) error {
 		}
 
 		beforeCol, hasBeforeCol := ctx.to.LookupColumnBefore(stmt.ID())
-		ctx.begin()
+		ctx.begin(ClauseKindAddColumn, nil, stmt)
+		ctx.raise(addColumnImpact(stmt))
 		ctx.writeString("ADD COLUMN ")
-		if err := format.SQL(&ctx.buf, stmt); err != nil {
+		if err := format.SQL(&ctx.buf, stmt, formatOptions(ctx.target)...); err != nil {
 			return err
 		}
 
@@ -402,7 +648,7 @@ func (ctx *alterCtx) writeAddColumn(columnNames ...string) error {
 }
 
 func (ctx *alterCtx) alterTableColumns() error {
-	columnNames := ctx.toColumns.Intersect(ctx.fromColumns)
+	columnNames := ctx.toColumns.Intersect(ctx.fromColumns).Difference(ctx.recreatedColumns)
 	for _, columnName := range columnNames.ToSlice() {
 		beforeColumnStmt, ok := ctx.from.LookupColumn(columnName)
 		if !ok {
@@ -414,15 +660,47 @@ func (ctx *alterCtx) alterTableColumns() error {
 			return fmt.Errorf("column not found in new schema: %q", columnName)
 		}
 
-		if reflect.DeepEqual(beforeColumnStmt, afterColumnStmt) {
+		// compare the canonical forms, because SHOW CREATE TABLE describes
+		// the same column differently from the hand-written DDL.
+		beforeCanonical := beforeColumnStmt.Canonical(ctx.version, ctx.from)
+		afterCanonical := afterColumnStmt.Canonical(ctx.version, ctx.to)
+		oldName, renamed := ctx.renamedColumns[columnName]
+		if !renamed && equalColumn(beforeCanonical, afterCanonical) {
+			continue
+		}
+		if !renamed {
+			oldName = afterColumnStmt.Name
+		}
+
+		if !renamed && equalColumnExceptVisibility(beforeCanonical, afterCanonical) {
+			// only the visibility is changed.
+			ctx.begin(ClauseKindAlterColumn, ctx.originalColumn(columnName), afterColumnStmt)
+			ctx.writeString("ALTER COLUMN ")
+			ctx.writeIdent(afterColumnStmt.Name)
+			if afterColumnStmt.Invisible {
+				ctx.writeString(" SET INVISIBLE")
+			} else {
+				ctx.writeString(" SET VISIBLE")
+			}
 			continue
 		}
 
-		ctx.begin()
+		if renamed && ctx.target != nil && ctx.target.SupportsRenameColumn() && equalColumn(beforeCanonical, afterCanonical) {
+			// only the name is changed.
+			ctx.begin(ClauseKindRenameColumn, ctx.originalColumn(columnName), afterColumnStmt)
+			ctx.writeString("RENAME COLUMN ")
+			ctx.writeIdent(oldName)
+			ctx.writeString(" TO ")
+			ctx.writeIdent(afterColumnStmt.Name)
+			continue
+		}
+
+		ctx.begin(ClauseKindChangeColumn, ctx.originalColumn(columnName), afterColumnStmt)
+		ctx.raise(columnImpact(beforeCanonical, afterCanonical))
 		ctx.writeString("CHANGE COLUMN ")
-		ctx.writeIdent(afterColumnStmt.Name)
+		ctx.writeIdent(oldName)
 		ctx.writeString(" ")
-		if err := format.SQL(&ctx.buf, afterColumnStmt); err != nil {
+		if err := format.SQL(&ctx.buf, afterColumnStmt, formatOptions(ctx.target)...); err != nil {
 			return err
 		}
 	}
@@ -433,7 +711,11 @@ func (ctx *alterCtx) dropTableIndexes() error {
 	indexes := ctx.fromIndexes.Difference(ctx.toIndexes)
 	// drop index after drop constraint.
 	// because cannot drop index if needed in a foreign key constraint
-	lazy := make([]model.Ident, 0, indexes.Cardinality())
+	type droppedIndex struct {
+		index *model.Index
+		name  model.Ident
+	}
+	lazy := make([]droppedIndex, 0, indexes.Cardinality())
 	for _, index := range indexes.ToSlice() {
 		indexStmt, ok := ctx.from.LookupIndex(index)
 		if !ok {
@@ -441,7 +723,8 @@ func (ctx *alterCtx) dropTableIndexes() error {
 		}
 
 		if indexStmt.Kind == model.IndexKindPrimaryKey {
-			ctx.begin()
+			ctx.begin(ClauseKindDropIndex, indexStmt, nil)
+			ctx.raise(ImpactLockHeavy) // changing the primary key rebuilds the table.
 			ctx.writeString("DROP PRIMARY KEY")
 			continue
 		}
@@ -456,25 +739,39 @@ func (ctx *alterCtx) dropTableIndexes() error {
 			indexName.Ident = name
 		}
 		if indexStmt.Kind != model.IndexKindForeignKey {
-			lazy = append(lazy, indexName.Ident)
+			lazy = append(lazy, droppedIndex{
+				index: indexStmt,
+				name:  indexName.Ident,
+			})
 			continue
 		}
 
-		ctx.begin()
+		ctx.begin(ClauseKindDropIndex, indexStmt, nil)
 		ctx.writeString("DROP FOREIGN KEY ")
 		ctx.writeIdent(indexName.Ident)
 	}
 
 	// drop index after drop CONSTRAINT
-	for _, indexName := range lazy {
-		ctx.begin()
+	for _, idx := range lazy {
+		ctx.begin(ClauseKindDropIndex, idx.index, nil)
 		ctx.writeString("DROP INDEX ")
-		ctx.writeIdent(indexName)
+		ctx.writeIdent(idx.name)
 	}
 
 	return nil
 }
 
+func (ctx *alterCtx) renameTableIndexes() error {
+	for _, rename := range ctx.renamedIndexes {
+		ctx.begin(ClauseKindRenameIndex, rename.from, rename.to)
+		ctx.writeString("RENAME INDEX ")
+		ctx.writeIdent(rename.from.Name.Ident)
+		ctx.writeString(" TO ")
+		ctx.writeIdent(rename.to.Name.Ident)
+	}
+	return nil
+}
+
 func (ctx *alterCtx) addTableIndexes() error {
 	indexes := ctx.toIndexes.Difference(ctx.fromIndexes)
 	// add index before add foreign key.
@@ -490,7 +787,10 @@ func (ctx *alterCtx) addTableIndexes() error {
 			continue
 		}
 
-		ctx.begin()
+		ctx.begin(ClauseKindAddIndex, nil, indexStmt)
+		if indexStmt.Kind == model.IndexKindPrimaryKey {
+			ctx.raise(ImpactLockHeavy) // changing the primary key rebuilds the table.
+		}
 		ctx.writeString("ADD ")
 		if err := format.SQL(&ctx.buf, indexStmt); err != nil {
 			return err
@@ -498,7 +798,7 @@ func (ctx *alterCtx) addTableIndexes() error {
 	}
 
 	for _, indexStmt := range lazy {
-		ctx.begin()
+		ctx.begin(ClauseKindAddIndex, nil, indexStmt)
 		ctx.writeString("ADD ")
 		if err := format.SQL(&ctx.buf, indexStmt); err != nil {
 			return err
@@ -508,6 +808,153 @@ func (ctx *alterCtx) addTableIndexes() error {
 	return nil
 }
 
+// alterTableIndexes changes the visibility of the indexes.
+func (ctx *alterCtx) alterTableIndexes() error {
+	for _, indexStmt := range ctx.to.Indexes {
+		before, ok := ctx.from.LookupIndex(indexStmt.ID())
+		if !ok || before.Invisible == indexStmt.Invisible {
+			continue
+		}
+
+		indexName := getIndexName(before)
+		if !indexName.Valid {
+			name, err := ctx.guessDropTableIndexName(before)
+			if err != nil {
+				return err
+			}
+			indexName.Valid = true
+			indexName.Ident = name
+		}
+
+		ctx.begin(ClauseKindAlterIndex, before, indexStmt)
+		ctx.writeString("ALTER INDEX ")
+		ctx.writeIdent(indexName.Ident)
+		if indexStmt.Invisible {
+			ctx.writeString(" INVISIBLE")
+		} else {
+			ctx.writeString(" VISIBLE")
+		}
+	}
+	return nil
+}
+
+// creationOnlyTableOptions are the table options that are only meaningful at creation time.
+// MySQL changes or ignores them after the table is created (e.g. AUTO_INCREMENT is
+// updated by inserting rows), so comparing them would cause perpetual drift.
+var creationOnlyTableOptions = map[string]struct{}{
+	"tableopt#auto_increment":  {},
+	"tableopt#data directory":  {},
+	"tableopt#index directory": {},
+}
+
+// defaultTableOptions are the default values of the table options.
+// The options that are not specified in the schema take the default value of the server.
+// Some of them have well-known defaults, but the others, such as ENGINE and DEFAULT CHARACTER SET,
+// depend on the server configuration. So we compare the options listed here if they are
+// specified in only one side. The others are applied if they are added to the schema,
+// and left as they are if they are removed from it.
+var defaultTableOptions = map[string]*model.TableOption{
+	"tableopt#comment":            model.NewTableOption("COMMENT", "", true),
+	"tableopt#key_block_size":     model.NewTableOption("KEY_BLOCK_SIZE", "0", false),
+	"tableopt#row_format":         model.NewTableOption("ROW_FORMAT", "DEFAULT", false),
+	"tableopt#stats_auto_recalc":  model.NewTableOption("STATS_AUTO_RECALC", "DEFAULT", false),
+	"tableopt#stats_persistent":   model.NewTableOption("STATS_PERSISTENT", "DEFAULT", false),
+	"tableopt#stats_sample_pages": model.NewTableOption("STATS_SAMPLE_PAGES", "DEFAULT", false),
+}
+
+func (ctx *alterCtx) alterTableOptions() error {
+	// if an option is specified more than once, the last one wins.
+	fromOptions := make(map[string]*model.TableOption, len(ctx.from.Options))
+	for _, opt := range ctx.from.Options {
+		fromOptions[opt.ID()] = opt
+	}
+	toOptions := make(map[string]*model.TableOption, len(ctx.to.Options))
+	for _, opt := range ctx.to.Options {
+		toOptions[opt.ID()] = opt
+	}
+
+	// added or changed options
+	seen := newSet()
+	for _, opt := range ctx.to.Options {
+		id := opt.ID()
+		if _, ok := creationOnlyTableOptions[id]; ok {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen.Add(id)
+
+		opt = toOptions[id]
+		before, ok := fromOptions[id]
+		if !ok {
+			before = defaultTableOptions[id]
+		}
+		// if the default value is unknown, the option specified explicitly is applied.
+		if before != nil && equalTableOption(before, opt) {
+			continue
+		}
+		if err := ctx.writeTableOption(fromOptions[id], opt); err != nil {
+			return err
+		}
+	}
+
+	// removed options
+	for _, opt := range ctx.from.Options {
+		id := opt.ID()
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen.Add(id)
+		if _, ok := toOptions[id]; ok {
+			continue
+		}
+
+		after, ok := defaultTableOptions[id]
+		if !ok {
+			// the default value is unknown.
+			continue
+		}
+		if equalTableOption(fromOptions[id], after) {
+			continue
+		}
+		if err := ctx.writeTableOption(fromOptions[id], after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTableOption writes the table option after.
+// before is the option specified in the old schema, and it may be nil.
+func (ctx *alterCtx) writeTableOption(before, after *model.TableOption) error {
+	if before == nil {
+		ctx.begin(ClauseKindTableOption, nil, after)
+	} else {
+		ctx.begin(ClauseKindTableOption, before, after)
+	}
+	ctx.raise(tableOptionImpact(after))
+	return format.SQL(&ctx.buf, after)
+}
+
+// equalTableOption returns whether table option a and b have same value.
+// The aliases of the character sets and the collations, such as utf8 and utf8mb3, have same value.
+func equalTableOption(a, b *model.TableOption) bool {
+	a, b = a.Canonical(), b.Canonical()
+	if a.ID() != b.ID() {
+		return false
+	}
+	if a.NeedQuotes != b.NeedQuotes {
+		return false
+	}
+	if a.NeedQuotes {
+		// quoted values, such as COMMENT, are case-sensitive.
+		return a.Value == b.Value
+	}
+	// the others, such as ENGINE and DEFAULT CHARACTER SET, are case-insensitive.
+	return strings.EqualFold(a.Value, b.Value)
+}
+
 func (ctx *alterCtx) guessDropTableIndexName(indexStmt *model.Index) (name model.Ident, err error) {
 	cur := ctx.cur
 	if cur == nil {
@@ -540,6 +987,131 @@ LOOP:
 	return "", fmt.Errorf("can not drop index without name: %q", indexStmt.ID())
 }
 
+// matchAnonymousIndexes matches the indexes without names in b to the indexes with the same definition in a.
+// MySQL names the anonymous indexes automatically, so the named one in a is the same index.
+// The matched IDs are added to both aIndexes and bIndexes, so they are neither dropped nor added.
+func matchAnonymousIndexes(a, b *model.Table, aIndexes, bIndexes set) {
+	matched := newSet()
+	for _, idx := range b.Indexes {
+		if getIndexName(idx).Valid || aIndexes.Contains(idx.ID()) {
+			continue
+		}
+		for _, candidate := range a.Indexes {
+			id := candidate.ID()
+			if bIndexes.Contains(id) || matched.Contains(id) || !equalIndex(candidate, idx) {
+				continue
+			}
+			matched.Add(id)
+			aIndexes.Add(idx.ID())
+			bIndexes.Add(id)
+			break
+		}
+	}
+}
+
+// matchRenamedIndexes finds the indexes that are dropped and added again with the same definition,
+// and marks them as kept. The indexes can be renamed by RENAME INDEX instead.
+func matchRenamedIndexes(from, to *model.Table, fromIndexes, toIndexes set) []indexRename {
+	var renames []indexRename
+	for _, idx := range to.Indexes {
+		if !isRenamableIndex(idx) || fromIndexes.Contains(idx.ID()) {
+			continue
+		}
+		for _, candidate := range from.Indexes {
+			id := candidate.ID()
+			// RENAME INDEX and ALTER INDEX can't be combined, so the index with the different visibility is recreated.
+			if !isRenamableIndex(candidate) || toIndexes.Contains(id) || !equalIndex(candidate, idx) || candidate.Invisible != idx.Invisible {
+				continue
+			}
+			renames = append(renames, indexRename{from: candidate, to: idx})
+			fromIndexes.Add(idx.ID())
+			toIndexes.Add(id)
+			break
+		}
+	}
+	return renames
+}
+
+// isRenamableIndex returns whether the index can be renamed by RENAME INDEX.
+// The primary key and foreign keys can't be renamed.
+func isRenamableIndex(idx *model.Index) bool {
+	switch idx.Kind {
+	case model.IndexKindNormal, model.IndexKindUnique, model.IndexKindFullText, model.IndexKindSpatial:
+		return idx.Name.Valid
+	}
+	return false
+}
+
+// keepForeignKeyIndexes keeps the indexes in from that are created implicitly for the foreign keys in to.
+// MySQL creates an index for a foreign key if there is no index where the foreign key columns are listed
+// as the first columns, and the index can't be dropped while the foreign key exists.
+func keepForeignKeyIndexes(from, to *model.Table, toIndexes set) {
+	for _, fk := range to.Indexes {
+		if fk.Kind != model.IndexKindForeignKey || hasIndexPrefix(to.Indexes, fk.Columns) {
+			continue
+		}
+		for _, idx := range from.Indexes {
+			if idx.Kind == model.IndexKindNormal && !toIndexes.Contains(idx.ID()) && equalIndexColumns(idx.Columns, fk.Columns) {
+				toIndexes.Add(idx.ID())
+				break
+			}
+		}
+	}
+}
+
+// ignoreRedundantIndexes ignores the indexes that model.Table.Normalize adds for the named foreign keys,
+// if another index can be used for the foreign key. MySQL doesn't create such indexes.
+// e.g. SHOW CREATE TABLE shows the index that MySQL has created for an anonymous foreign key,
+// and the index is named after the column, not the constraint.
+func ignoreRedundantIndexes(table *model.Table, fromIndexes, toIndexes set) {
+	for _, fk := range table.Indexes {
+		if fk.Kind != model.IndexKindForeignKey || !fk.ConstraintName.Valid {
+			continue
+		}
+		for i, idx := range table.Indexes {
+			if idx.Kind != model.IndexKindNormal || !idx.Name.Valid || idx.Name.Ident != fk.ConstraintName.Ident {
+				continue
+			}
+			if !equalIndexColumns(idx.Columns, fk.Columns) {
+				continue
+			}
+			others := make([]*model.Index, 0, len(table.Indexes)-1)
+			others = append(others, table.Indexes[:i]...)
+			others = append(others, table.Indexes[i+1:]...)
+			if hasIndexPrefix(others, fk.Columns) {
+				fromIndexes.Add(idx.ID())
+				toIndexes.Add(idx.ID())
+			}
+		}
+	}
+}
+
+// hasIndexPrefix returns whether indexes contain an index other than foreign keys,
+// where columns are listed as the first columns in the same order.
+func hasIndexPrefix(indexes []*model.Index, columns []*model.IndexColumn) bool {
+	for _, idx := range indexes {
+		if idx.Kind == model.IndexKindForeignKey || len(idx.Columns) < len(columns) {
+			continue
+		}
+		if equalIndexColumns(idx.Columns[:len(columns)], columns) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalIndexColumns(a, b []*model.IndexColumn) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID() != b[i].ID() {
+			return false
+		}
+	}
+	return true
+}
+
 func getIndexName(idx *model.Index) model.MaybeIdent {
 	if idx.Name.Valid {
 		return idx.Name
@@ -550,6 +1122,33 @@ func getIndexName(idx *model.Index) model.MaybeIdent {
 	return model.MaybeIdent{}
 }
 
+// equalColumn returns whether column a and b have same definition, excluding the annotations.
+func equalColumn(a, b *model.TableColumn) bool {
+	if a.Generated.Valid != b.Generated.Valid || generatedStorage(a) != generatedStorage(b) {
+		return false
+	}
+	if a.Generated.Valid && !equalExpr(a.Generated.Value, b.Generated.Value) {
+		return false
+	}
+
+	aa, bb := *a, *b
+	aa.RenamedFrom = model.MaybeIdent{}
+	bb.RenamedFrom = model.MaybeIdent{}
+	aa.Generated, aa.GeneratedStorage = model.MaybeString{}, model.GeneratedStorageNone
+	bb.Generated, bb.GeneratedStorage = model.MaybeString{}, model.GeneratedStorageNone
+	return reflect.DeepEqual(&aa, &bb)
+}
+
+// equalColumnExceptVisibility returns whether column a and b differ only in their visibility.
+func equalColumnExceptVisibility(a, b *model.TableColumn) bool {
+	if a.Invisible == b.Invisible {
+		return false
+	}
+	aa, bb := *a, *b
+	aa.Invisible, bb.Invisible = false, false
+	return equalColumn(&aa, &bb)
+}
+
 // equalIndex returns whether index a and b have same definition, excluding their names.
 func equalIndex(a, b *model.Index) bool {
 	if a.Table != b.Table {
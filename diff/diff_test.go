@@ -9,9 +9,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/shogo82148/schemalex-deploy"
 	"github.com/shogo82148/schemalex-deploy/diff"
 	"github.com/shogo82148/schemalex-deploy/internal/database"
 	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
 )
 
 type Spec struct {
@@ -295,6 +297,405 @@ var specs = []Spec{
 		},
 	},
 
+	// table options
+	{
+		Name: "change table options",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COMMENT 'fuga is good'",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` DEFAULT CHARACTER SET = utf8, COMMENT = 'fuga is good'",
+		},
+	},
+	{
+		Name: "table options are case-insensitive",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB ROW_FORMAT=DYNAMIC",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=innodb ROW_FORMAT=dynamic",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "table character set and collation aliases",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8mb3_general_ci",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "remove table options",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB ROW_FORMAT=DYNAMIC COMMENT 'fuga is good'",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` ROW_FORMAT = DEFAULT, COMMENT = ''",
+		},
+	},
+	{
+		Name: "add table options without the known defaults",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` ENGINE = InnoDB, DEFAULT CHARACTER SET = utf8mb4",
+		},
+	},
+	{
+		Name: "ignore creation-only table options",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL AUTO_INCREMENT, PRIMARY KEY (`id`) ) AUTO_INCREMENT = 10",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL AUTO_INCREMENT, PRIMARY KEY (`id`) ) AUTO_INCREMENT = 20",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "change columns and table options",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL ) ENGINE=MyISAM",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `c` INTEGER NOT NULL ) ENGINE=InnoDB",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` ADD COLUMN `c` INT (11) NOT NULL AFTER `id`, ENGINE = InnoDB",
+		},
+	},
+	{
+		Name: "rename table",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"-- schemalex:renamed-from hoge\nCREATE TABLE `fuga` ( `id` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"RENAME TABLE `hoge` TO `fuga`",
+		},
+	},
+	{
+		Name: "rename table and add column",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"/* schemalex:renamed-from `hoge` */ CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `c` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"RENAME TABLE `hoge` TO `fuga`",
+			"ALTER TABLE `fuga` ADD COLUMN `c` INT (11) NOT NULL AFTER `id`",
+		},
+	},
+	{
+		Name: "rename referenced table",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) )",
+			"CREATE TABLE `bar` ( `id` INTEGER NOT NULL, `hoge_id` INTEGER NOT NULL, INDEX `hoge_id` (`hoge_id`), CONSTRAINT `fk` FOREIGN KEY (`hoge_id`) REFERENCES `hoge` (`id`) )",
+		},
+		After: []string{
+			"-- schemalex:renamed-from hoge\nCREATE TABLE `fuga` ( `id` INTEGER NOT NULL, PRIMARY KEY (`id`) )",
+			"CREATE TABLE `bar` ( `id` INTEGER NOT NULL, `hoge_id` INTEGER NOT NULL, INDEX `hoge_id` (`hoge_id`), CONSTRAINT `fk` FOREIGN KEY (`hoge_id`) REFERENCES `fuga` (`id`) )",
+		},
+		Expect: []string{
+			"RENAME TABLE `hoge` TO `fuga`",
+		},
+	},
+	{
+		Name: "already renamed table",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"-- schemalex:renamed-from hoge\nCREATE TABLE `fuga` ( `id` INTEGER NOT NULL )",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "create view",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE VIEW `v1` AS SELECT `id` FROM `hoge`",
+			"CREATE VIEW `v2` AS SELECT `id` FROM `v1`",
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `c` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"ALTER TABLE `hoge` ADD COLUMN `c` INT (11) NOT NULL AFTER `id`",
+			"CREATE OR REPLACE VIEW `v1` AS SELECT `id` FROM `hoge`",
+			"CREATE OR REPLACE VIEW `v2` AS SELECT `id` FROM `v1`",
+		},
+	},
+	{
+		Name: "drop view",
+		Before: []string{
+			"CREATE VIEW `v` AS SELECT `id` FROM `hoge`",
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{},
+		Expect: []string{
+			"DROP VIEW `v`",
+			"DROP TABLE `hoge`",
+		},
+	},
+	{
+		Name: "change view",
+		Before: []string{
+			"CREATE VIEW `v` AS SELECT `id` FROM `hoge`",
+		},
+		After: []string{
+			"CREATE ALGORITHM = MERGE VIEW `v` AS SELECT `id` FROM `hoge` WHERE `id` > 0",
+		},
+		Expect: []string{
+			"CREATE OR REPLACE ALGORITHM = MERGE VIEW `v` AS SELECT `id` FROM `hoge` WHERE `id` > 0",
+		},
+	},
+	{
+		Name: "not change view",
+		Before: []string{
+			"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select `hoge`.`id` AS `id` from `hoge`",
+		},
+		After: []string{
+			"CREATE VIEW v AS\n  SELECT hoge.id AS id\n  FROM hoge",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "replace view with table",
+		Before: []string{
+			"CREATE VIEW `hoge` AS SELECT 1 AS `id`",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"DROP VIEW `hoge`",
+			"CREATE TABLE `hoge` (\n`id` INT (11) NOT NULL\n)",
+		},
+	},
+	{
+		Name: "create routines",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+			"CREATE FUNCTION `f` (a INT) RETURNS INT DETERMINISTIC RETURN a + 1",
+			"CREATE PROCEDURE `p` () BEGIN SELECT 1; SELECT 2; END",
+			"CREATE VIEW `v` AS SELECT `f`(`id`) FROM `hoge`",
+		},
+		Expect: []string{
+			"CREATE FUNCTION `f` (a INT) RETURNS INT DETERMINISTIC\nRETURN a + 1",
+			"CREATE PROCEDURE `p` ()\nBEGIN SELECT 1; SELECT 2; END",
+			"CREATE OR REPLACE VIEW `v` AS SELECT `f`(`id`) FROM `hoge`",
+		},
+	},
+	{
+		Name: "drop routines",
+		Before: []string{
+			"CREATE FUNCTION `f` (a INT) RETURNS INT DETERMINISTIC RETURN a + 1",
+			"CREATE PROCEDURE `p` () BEGIN SELECT 1; END",
+		},
+		After: []string{},
+		Expect: []string{
+			"DROP FUNCTION `f`",
+			"DROP PROCEDURE `p`",
+		},
+	},
+	{
+		Name: "change routine",
+		Before: []string{
+			"CREATE PROCEDURE `p` () BEGIN SELECT 1; END",
+		},
+		After: []string{
+			"CREATE PROCEDURE `p` () BEGIN SELECT 2; END",
+		},
+		Expect: []string{
+			"DROP PROCEDURE IF EXISTS `p`",
+			"CREATE PROCEDURE `p` ()\nBEGIN SELECT 2; END",
+		},
+	},
+	{
+		Name: "not change routine",
+		Before: []string{
+			"CREATE DEFINER=`root`@`%` FUNCTION `f`(`a` int) RETURNS int\n    DETERMINISTIC\nBEGIN\n  RETURN `a` + 1;\nEND",
+		},
+		After: []string{
+			"CREATE FUNCTION f (a INT) RETURNS INT CONTAINS SQL DETERMINISTIC SQL SECURITY DEFINER BEGIN RETURN a + 1; END",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "procedure and function with same name",
+		Before: []string{
+			"CREATE PROCEDURE `x` () SELECT 1",
+		},
+		After: []string{
+			"CREATE FUNCTION `x` () RETURNS INT RETURN 1",
+		},
+		Expect: []string{
+			"DROP PROCEDURE `x`",
+			"CREATE FUNCTION `x` () RETURNS INT\nRETURN 1",
+		},
+	},
+	{
+		Name:   "create trigger",
+		Before: []string{},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW SET NEW.a = 1",
+		},
+		Expect: []string{
+			"CREATE TABLE `hoge` (\n`id` INT (11) NOT NULL,\n`a` INT (11) NOT NULL\n)",
+			"CREATE TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW\nSET NEW.a = 1",
+		},
+	},
+	{
+		Name: "drop trigger",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW SET NEW.a = 1",
+		},
+		After: []string{},
+		Expect: []string{
+			"DROP TRIGGER `hoge_bi`",
+			"DROP TABLE `hoge`",
+		},
+	},
+	{
+		Name: "change trigger",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW SET NEW.a = 1",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, `b` INTEGER NOT NULL )",
+			"CREATE TRIGGER `hoge_bi` BEFORE UPDATE ON `hoge` FOR EACH ROW SET NEW.b = NEW.a",
+		},
+		Expect: []string{
+			"DROP TRIGGER IF EXISTS `hoge_bi`",
+			"ALTER TABLE `hoge` ADD COLUMN `b` INT (11) NOT NULL AFTER `a`",
+			"CREATE TRIGGER `hoge_bi` BEFORE UPDATE ON `hoge` FOR EACH ROW\nSET NEW.b = NEW.a",
+		},
+	},
+	{
+		Name: "not change trigger",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE DEFINER=`root`@`%` TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW SET NEW.`a` = 1",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE TRIGGER hoge_bi before insert ON hoge FOR EACH ROW set new.a = 1",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "rename table with trigger",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW SET NEW.a = 1",
+		},
+		After: []string{
+			"-- schemalex:renamed-from hoge\nCREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			"CREATE TRIGGER `hoge_bi` BEFORE INSERT ON `fuga` FOR EACH ROW SET NEW.a = 1",
+		},
+		Expect: []string{
+			"RENAME TABLE `hoge` TO `fuga`",
+		},
+	},
+	{
+		Name:   "create event",
+		Before: []string{},
+		After: []string{
+			"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO DELETE FROM `log`",
+		},
+		Expect: []string{
+			"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY\nDO DELETE FROM `log`",
+		},
+	},
+	{
+		Name: "drop event",
+		Before: []string{
+			"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO DELETE FROM `log`",
+		},
+		After: []string{},
+		Expect: []string{
+			"DROP EVENT `e`",
+		},
+	},
+	{
+		Name: "alter event",
+		Before: []string{
+			"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO DELETE FROM `log`",
+		},
+		After: []string{
+			"CREATE EVENT `e` ON SCHEDULE EVERY 1 HOUR ON COMPLETION PRESERVE DISABLE COMMENT 'hourly' DO DELETE FROM `log` LIMIT 1000",
+		},
+		Expect: []string{
+			"ALTER EVENT `e` ON SCHEDULE EVERY 1 HOUR ON COMPLETION PRESERVE DISABLE COMMENT 'hourly'\nDO DELETE FROM `log` LIMIT 1000",
+		},
+	},
+	{
+		Name: "not change event",
+		Before: []string{
+			"CREATE DEFINER=`root`@`%` EVENT `e` ON SCHEDULE EVERY 1 DAY STARTS '2024-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE DO delete from log",
+		},
+		After: []string{
+			"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO DELETE FROM `log`",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "rename column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, INDEX `a` (`a`) )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` INTEGER NOT NULL, INDEX `a` (`b`) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` CHANGE COLUMN `a` `b` INT (11) NOT NULL",
+		},
+	},
+	{
+		Name: "rename and change column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `b` BIGINT NOT NULL # schemalex:renamed-from a\n)",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` CHANGE COLUMN `a` `b` BIGINT (20) NOT NULL",
+		},
+	},
+	{
+		Name: "already renamed column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, `b` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL, -- schemalex:renamed-from a\n`b` INTEGER NOT NULL )",
+		},
+		Expect: []string{},
+	},
+
 	// geometry types
 	{
 		Name: "add columns with srid",
@@ -424,10 +825,391 @@ KEY bar_fk (integer_s),
 KEY foo_idx (int_s),
 CONSTRAINT bar_fk FOREIGN KEY (integer_s) REFERENCES bar (id)
 )`},
-		Expect: []string{},
+		// the table options specified explicitly are applied, even if their defaults are unknown.
+		Expect: []string{
+			"ALTER TABLE `bar` ENGINE = InnoDB, DEFAULT CHARACTER SET = utf8mb4",
+		},
 	},
-}
-
+	{
+		Name: "add generated column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a + 1) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` ADD COLUMN `b` INT (11) GENERATED ALWAYS AS (a + 1) VIRTUAL AFTER `a`",
+		},
+	},
+	{
+		Name: "not change generated column shown by show create table",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` int(11) NOT NULL, `b` int(11) GENERATED ALWAYS AS ((`a` + 1)) VIRTUAL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a + 1) )",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "change expression of generated column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a + 1) STORED )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a + 2) STORED )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` CHANGE COLUMN `b` `b` INT (11) GENERATED ALWAYS AS (a + 2) STORED",
+		},
+	},
+	{
+		Name: "change virtual generated column to stored",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a + 1) VIRTUAL, `c` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a + 1) STORED, `c` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` DROP COLUMN `b`, ADD COLUMN `b` INT (11) GENERATED ALWAYS AS (a + 1) STORED AFTER `a`",
+		},
+	},
+	{
+		Name: "change stored generated column to regular column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a * 2) STORED )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` CHANGE COLUMN `b` `b` INT (11) DEFAULT NULL",
+		},
+	},
+	{
+		Name: "change regular column to stored generated column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER AS (a * 2) STORED )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` CHANGE COLUMN `b` `b` INT (11) GENERATED ALWAYS AS (a * 2) STORED",
+		},
+	},
+	{
+		Name: "add check constraint",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, CONSTRAINT `a_positive` CHECK (a > 0) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` ADD CONSTRAINT `a_positive` CHECK (a > 0)",
+		},
+	},
+	{
+		Name: "drop check constraint with column",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, `b` INTEGER NOT NULL CHECK (b > 0) )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` DROP CHECK `fuga_chk_1`, DROP COLUMN `b`",
+		},
+	},
+	{
+		Name: "change expression of check constraint",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, CONSTRAINT `a_positive` CHECK (a > 0) )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, CONSTRAINT `a_positive` CHECK (a >= 0) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` DROP CHECK `a_positive`, ADD CONSTRAINT `a_positive` CHECK (a >= 0)",
+		},
+	},
+	{
+		Name: "change enforcement of check constraint",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, CONSTRAINT `a_positive` CHECK (a > 0) )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL, CONSTRAINT `a_positive` CHECK (a > 0) NOT ENFORCED )",
+		},
+		Expect: []string{
+			"ALTER TABLE `fuga` ALTER CHECK `a_positive` NOT ENFORCED",
+		},
+	},
+	{
+		Name: "not change check constraint shown by show create table",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` int NOT NULL, CONSTRAINT `fuga_chk_1` CHECK ((`a` > 0)) /*!80016 NOT ENFORCED */ )",
+		},
+		After: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL CHECK (a > 0) NOT ENFORCED )",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "rename table with check constraint",
+		Before: []string{
+			"CREATE TABLE `fuga` ( `a` INTEGER NOT NULL CHECK (a > 0) )",
+		},
+		After: []string{
+			"-- schemalex:renamed-from fuga\nCREATE TABLE `hoge` ( `a` INTEGER NOT NULL CHECK (a > 0) )",
+		},
+		Expect: []string{
+			"RENAME TABLE `fuga` TO `hoge`",
+		},
+	},
+	{
+		Name: "live schema is same as the hand-written one",
+		Before: []string{
+			"CREATE TABLE `fuga` (\n" +
+				"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+				"  `c` char(10) CHARACTER SET ascii COLLATE ascii_general_ci DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=5 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+			"CREATE TABLE `hoge` (\n" +
+				"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+				"  `flag` tinyint(1) NOT NULL DEFAULT '0',\n" +
+				"  `fuga_id` bigint unsigned NOT NULL,\n" +
+				"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  KEY `created_at` (`created_at`),\n" +
+				"  KEY `fuga_id` (`fuga_id`),\n" +
+				"  CONSTRAINT `hoge_ibfk_1` FOREIGN KEY (`fuga_id`) REFERENCES `fuga` (`id`) ON DELETE CASCADE\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=12 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+		},
+		After: []string{
+			"CREATE TABLE fuga ( id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY, c CHAR(10) CHARACTER SET ascii ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4",
+			"CREATE TABLE hoge ( " +
+				"id INTEGER NOT NULL AUTO_INCREMENT, " +
+				"flag BOOLEAN NOT NULL DEFAULT FALSE, " +
+				"fuga_id BIGINT UNSIGNED NOT NULL, " +
+				"created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"PRIMARY KEY (id), " +
+				"INDEX (created_at), " +
+				"FOREIGN KEY (fuga_id) REFERENCES fuga (id) ON DELETE CASCADE " +
+				") ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "name anonymous index",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, INDEX (`a`) )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, INDEX `a` (`a`) )",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "change collation from the default one",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `c` CHAR (10) CHARACTER SET ascii COLLATE ascii_general_ci )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `c` CHAR (10) CHARACTER SET ascii COLLATE ascii_bin )",
+		},
+		Expect: []string{
+			"ALTER TABLE `hoge` CHANGE COLUMN `c` `c` CHAR (10) CHARACTER SET `ascii` COLLATE `ascii_bin` DEFAULT NULL",
+		},
+	},
+	{
+		Name: "expression default from SHOW CREATE TABLE",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` BINARY(16) NOT NULL DEFAULT (uuid_to_bin(uuid())), `email` VARCHAR(255) NOT NULL, KEY `idx_email` ((lower(`email`))) )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` BINARY(16) NOT NULL DEFAULT (UUID_TO_BIN(UUID())), `email` VARCHAR(255) NOT NULL, INDEX idx_email ((LOWER(email))) )",
+		},
+		Expect: []string{},
+	},
+	{
+		Name: "change expression default and functional index",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` BINARY(16) NOT NULL DEFAULT (UUID_TO_BIN(UUID())), `email` VARCHAR(255) NOT NULL, INDEX idx_email ((LOWER(email))) )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` BINARY(16) NOT NULL DEFAULT (UUID_TO_BIN(UUID(), 1)), `email` VARCHAR(255) NOT NULL, INDEX idx_email ((UPPER(email))) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `hoge` DROP INDEX `idx_email`, CHANGE COLUMN `id` `id` BINARY (16) NOT NULL DEFAULT (UUID_TO_BIN(UUID(), 1)), ADD INDEX `idx_email` ((UPPER(email)))",
+		},
+	},
+	{
+		Name: "change visibility",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, `b` INTEGER NOT NULL INVISIBLE, INDEX `idx_a` (`a`), INDEX `idx_b` (`b`) INVISIBLE )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL INVISIBLE, `b` INTEGER NOT NULL, INDEX `idx_a` (`a`) INVISIBLE, INDEX `idx_b` (`b`) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `hoge` ALTER COLUMN `a` SET INVISIBLE, ALTER COLUMN `b` SET VISIBLE, ALTER INDEX `idx_a` INVISIBLE, ALTER INDEX `idx_b` VISIBLE",
+		},
+	},
+	{
+		Name: "change visibility with other changes",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, INDEX `idx_a` (`id`) )",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` BIGINT NOT NULL INVISIBLE, INDEX `idx_a` (`a`) INVISIBLE )",
+		},
+		Expect: []string{
+			"ALTER TABLE `hoge` DROP INDEX `idx_a`, CHANGE COLUMN `a` `a` BIGINT (20) NOT NULL INVISIBLE, ADD INDEX `idx_a` (`a`) INVISIBLE",
+		},
+	},
+	{
+		Name: "change tablespace",
+		Before: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL ) TABLESPACE ts1",
+		},
+		After: []string{
+			"CREATE TABLE `hoge` ( `id` INTEGER NOT NULL ) TABLESPACE = `ts2`",
+		},
+		Expect: []string{
+			"ALTER TABLE `hoge` TABLESPACE = `ts2`",
+		},
+	},
+	{
+		Name: "change union tables",
+		Before: []string{
+			"CREATE TABLE `total` ( `a` INTEGER NOT NULL ) ENGINE = MERGE UNION = (t1, t2)",
+		},
+		After: []string{
+			"CREATE TABLE `total` ( `a` INTEGER NOT NULL ) ENGINE = MERGE UNION = (`t1`, `t2`, `t3`)",
+		},
+		Expect: []string{
+			"ALTER TABLE `total` UNION = (`t1`, `t2`, `t3`)",
+		},
+	},
+	{
+		Name: "partition table",
+		Before: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL )",
+		},
+		After: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN MAXVALUE)",
+		},
+		Expect: []string{
+			"ALTER TABLE `log` PARTITION BY RANGE (`y`) (PARTITION `p0` VALUES LESS THAN (2000), PARTITION `p1` VALUES LESS THAN MAXVALUE)",
+		},
+	},
+	{
+		Name: "remove partitioning",
+		Before: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN MAXVALUE)",
+		},
+		After: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL, PRIMARY KEY (`id`) )",
+		},
+		Expect: []string{
+			"ALTER TABLE `log` REMOVE PARTITIONING",
+			"ALTER TABLE `log` ADD PRIMARY KEY (`id`)",
+		},
+	},
+	{
+		Name: "rotate range partitions",
+		Before: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p2000 VALUES LESS THAN (2001), PARTITION p2001 VALUES LESS THAN (2002) ENGINE = InnoDB)",
+		},
+		After: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p2001 VALUES LESS THAN (2002), PARTITION p2002 VALUES LESS THAN (2003))",
+		},
+		Expect: []string{
+			"ALTER TABLE `log` DROP PARTITION `p2000`",
+			"ALTER TABLE `log` ADD PARTITION (PARTITION `p2002` VALUES LESS THAN (2003))",
+		},
+	},
+	{
+		Name: "split range partition",
+		Before: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+		},
+		After: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN (2010), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+		},
+		Expect: []string{
+			"ALTER TABLE `log` REORGANIZE PARTITION `pmax` INTO (PARTITION `p1` VALUES LESS THAN (2010), PARTITION `pmax` VALUES LESS THAN MAXVALUE)",
+		},
+	},
+	{
+		Name: "change range partition",
+		Before: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN (2010), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+		},
+		After: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN (2020), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+		},
+		Expect: []string{
+			"ALTER TABLE `log` REORGANIZE PARTITION `p1`, `pmax` INTO (PARTITION `p1` VALUES LESS THAN (2020), PARTITION `pmax` VALUES LESS THAN MAXVALUE)",
+		},
+	},
+	{
+		Name: "change range partition options",
+		Before: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+		},
+		After: []string{
+			"CREATE TABLE `log` ( `id` INTEGER NOT NULL, `y` INTEGER NOT NULL ) PARTITION BY RANGE (`y`) (PARTITION p0 VALUES LESS THAN (2000) COMMENT 'old', PARTITION pmax VALUES LESS THAN MAXVALUE)",
+		},
+		Expect: []string{
+			"ALTER TABLE `log` REORGANIZE PARTITION `p0` INTO (PARTITION `p0` VALUES LESS THAN (2000) COMMENT = 'old')",
+		},
+	},
+	{
+		Name: "add list partition",
+		Before: []string{
+			"CREATE TABLE `t` ( `k` INTEGER NOT NULL ) PARTITION BY LIST (k) (PARTITION p1 VALUES IN (1), PARTITION p3 VALUES IN (3))",
+		},
+		After: []string{
+			"CREATE TABLE `t` ( `k` INTEGER NOT NULL ) PARTITION BY LIST (`k`) (PARTITION p1 VALUES IN (1), PARTITION p2 VALUES IN (2), PARTITION p3 VALUES IN (3))",
+		},
+		Expect: []string{
+			"ALTER TABLE `t` ADD PARTITION (PARTITION `p2` VALUES IN (2))",
+		},
+	},
+	{
+		Name: "change the number of hash partitions",
+		Before: []string{
+			"CREATE TABLE `t` ( `k` INTEGER NOT NULL ) PARTITION BY HASH (k) PARTITIONS 4",
+			"CREATE TABLE `u` ( `k` INTEGER NOT NULL ) PARTITION BY KEY (k) PARTITIONS 4",
+		},
+		After: []string{
+			"CREATE TABLE `t` ( `k` INTEGER NOT NULL ) PARTITION BY HASH (k) PARTITIONS 6",
+			"CREATE TABLE `u` ( `k` INTEGER NOT NULL ) PARTITION BY KEY (k) PARTITIONS 2",
+		},
+		Expect: []string{
+			"ALTER TABLE `t` ADD PARTITION PARTITIONS 2",
+			"ALTER TABLE `u` COALESCE PARTITION 2",
+		},
+	},
+	{
+		Name: "change partitioning type",
+		Before: []string{
+			"CREATE TABLE `t` ( `k` INTEGER NOT NULL ) PARTITION BY HASH (k) PARTITIONS 4",
+		},
+		After: []string{
+			"CREATE TABLE `t` ( `k` INTEGER NOT NULL ) PARTITION BY LINEAR KEY (k) PARTITIONS 4",
+		},
+		Expect: []string{
+			"ALTER TABLE `t` PARTITION BY LINEAR KEY (`k`) PARTITIONS 4",
+		},
+	},
+}
+
 func joinQueries(queries []string) string {
 	var buf strings.Builder
 	for _, q := range queries {
@@ -465,6 +1247,663 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+func TestDiffWithServerVersion(t *testing.T) {
+	// the output of SHOW CREATE TABLE
+	const live = "CREATE TABLE `hoge` (\n" +
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `total` int(5) DEFAULT NULL,\n" +
+		"  `name` varchar(20) CHARACTER SET utf8 DEFAULT NULL,\n" +
+		"  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n"
+	const hand = "CREATE TABLE hoge (\n" +
+		"  id INT NOT NULL AUTO_INCREMENT,\n" +
+		"  total INT,\n" +
+		"  name VARCHAR(20) CHARACTER SET utf8mb3,\n" +
+		"  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE NOW(),\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n"
+
+	tests := []struct {
+		version model.ServerVersion
+		want    string
+	}{
+		{
+			// MySQL 8.0.19 and later ignore the display width.
+			version: model.ServerVersionMySQL80,
+			want:    "",
+		},
+		{
+			version: model.ServerVersionMariaDB,
+			want:    "ALTER TABLE `hoge` CHANGE COLUMN `total` `total` INT (11) DEFAULT NULL;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := diff.Strings(&buf, live, hand, diff.WithServerVersion(tt.version)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("mismatch (-want/+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffWithServerVersion_TableCharset(t *testing.T) {
+	tests := []struct {
+		name string
+		live string // the output of SHOW CREATE TABLE
+		hand string
+	}{
+		{
+			name: "character set of the table",
+			live: "CREATE TABLE `hoge` (\n" +
+				"  `name` varchar(20) DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;\n",
+			hand: "CREATE TABLE hoge (\n" +
+				"  name VARCHAR(20) CHARACTER SET utf8mb4\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;\n",
+		},
+		{
+			name: "collation of the table",
+			live: "CREATE TABLE `hoge` (\n" +
+				"  `name` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;\n",
+			hand: "CREATE TABLE hoge (\n" +
+				"  name VARCHAR(20)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := diff.Strings(&buf, tt.live, tt.hand, diff.WithServerVersion(model.ServerVersionMySQL80)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff("", buf.String()); diff != "" {
+				t.Errorf("mismatch (-want/+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffWithServerVersion_Syntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		version model.ServerVersion
+		before  string
+		after   string
+		want    string
+	}{
+		{
+			name:    "rename column on MySQL 8.0",
+			version: model.ServerVersionMySQL80,
+			before:  "CREATE TABLE `hoge` ( `a` INT NOT NULL );",
+			after:   "CREATE TABLE `hoge` ( -- schemalex:renamed-from a\n`b` INT NOT NULL );",
+			want:    "ALTER TABLE `hoge` RENAME COLUMN `a` TO `b`;\n",
+		},
+		{
+			name:    "rename and change column on MySQL 8.0",
+			version: model.ServerVersionMySQL80,
+			before:  "CREATE TABLE `hoge` ( `a` INT NOT NULL );",
+			after:   "CREATE TABLE `hoge` ( -- schemalex:renamed-from a\n`b` BIGINT NOT NULL );",
+			want:    "ALTER TABLE `hoge` CHANGE COLUMN `a` `b` BIGINT NOT NULL;\n",
+		},
+		{
+			name:    "rename column on MySQL 5.7",
+			version: model.ServerVersionMySQL57,
+			before:  "CREATE TABLE `hoge` ( `a` INT NOT NULL );",
+			after:   "CREATE TABLE `hoge` ( -- schemalex:renamed-from a\n`b` INT NOT NULL );",
+			want:    "ALTER TABLE `hoge` CHANGE COLUMN `a` `b` INT (11) NOT NULL;\n",
+		},
+		{
+			name:    "rename index on MySQL 5.7",
+			version: model.ServerVersionMySQL57,
+			before:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, INDEX `idx_a` (`a`) );",
+			after:   "CREATE TABLE `hoge` ( `a` INT NOT NULL, INDEX `idx_hoge_a` (`a`) );",
+			want:    "ALTER TABLE `hoge` RENAME INDEX `idx_a` TO `idx_hoge_a`;\n",
+		},
+		{
+			name:    "rename index on MariaDB 10.4",
+			version: model.ServerVersion{Flavor: model.FlavorMariaDB, Major: 10, Minor: 4, Patch: 0},
+			before:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, INDEX `idx_a` (`a`) );",
+			after:   "CREATE TABLE `hoge` ( `a` INT NOT NULL, INDEX `idx_hoge_a` (`a`) );",
+			want:    "ALTER TABLE `hoge` DROP INDEX `idx_a`, ADD INDEX `idx_hoge_a` (`a`);\n",
+		},
+		{
+			name:    "add column on MySQL 8.0",
+			version: model.ServerVersionMySQL80,
+			before:  "CREATE TABLE `hoge` ( `a` INT NOT NULL );",
+			after:   "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` BIGINT UNSIGNED NOT NULL );",
+			want:    "ALTER TABLE `hoge` ADD COLUMN `b` BIGINT UNSIGNED NOT NULL AFTER `a`;\n",
+		},
+		{
+			name:    "disable event on replica on MySQL 8.0",
+			version: model.ServerVersionMySQL80,
+			before:  "CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO DELETE FROM `log`;",
+			after:   "CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DISABLE ON SLAVE DO DELETE FROM `log`;",
+			want:    "ALTER EVENT `e` DISABLE ON REPLICA;\n",
+		},
+		{
+			name:    "disable event on replica on MySQL 5.7",
+			version: model.ServerVersionMySQL57,
+			before:  "CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO DELETE FROM `log`;",
+			after:   "CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DISABLE ON REPLICA DO DELETE FROM `log`;",
+			want:    "ALTER EVENT `e` DISABLE ON SLAVE;\n",
+		},
+		{
+			name:    "default collation of database on MySQL 8.0",
+			version: model.ServerVersionMySQL80,
+			before:  "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;",
+			after:   "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			want:    "",
+		},
+		{
+			name:    "default collation of database on MySQL 5.7",
+			version: model.ServerVersionMySQL57,
+			before:  "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;",
+			after:   "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			want:    "ALTER DATABASE `d` CHARACTER SET = utf8mb4;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := diff.Strings(&buf, tt.before, tt.after, diff.WithServerVersion(tt.version)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("mismatch (-want/+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffDatabase(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		current string
+		want    string
+	}{
+		{
+			name:   "change character set",
+			before: "CREATE DATABASE `d` DEFAULT CHARACTER SET latin1;",
+			after:  "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;",
+			want:   "ALTER DATABASE `d` CHARACTER SET = utf8mb4 COLLATE = utf8mb4_bin;\n",
+		},
+		{
+			name:   "change collation",
+			before: "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;",
+			after:  "CREATE DATABASE `d` COLLATE utf8mb4_general_ci;",
+			want:   "ALTER DATABASE `d` CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci;\n",
+		},
+		{
+			name:   "change encryption",
+			before: "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			after:  "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4 DEFAULT ENCRYPTION 'y';",
+			want:   "ALTER DATABASE `d` ENCRYPTION = 'Y';\n",
+		},
+		{
+			name:   "omitted options are not changed",
+			before: "CREATE DATABASE `d` DEFAULT CHARACTER SET latin1 DEFAULT ENCRYPTION 'Y';",
+			after:  "CREATE DATABASE `d`;",
+			want:   "",
+		},
+		{
+			name:   "charset alias",
+			before: "CREATE DATABASE `d` DEFAULT CHARSET utf8;",
+			after:  "CREATE DATABASE IF NOT EXISTS `d` CHARACTER SET utf8mb3;",
+			want:   "",
+		},
+		{
+			name:  "databases are not created",
+			after: "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			want:  "",
+		},
+		{
+			name:   "databases are not dropped",
+			before: "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			want:   "",
+		},
+		{
+			name:   "altered before creating tables",
+			before: "CREATE DATABASE `d` DEFAULT CHARACTER SET latin1;",
+			after:  "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4; CREATE TABLE `t` ( `id` INT NOT NULL );",
+			want: "ALTER DATABASE `d` CHARACTER SET = utf8mb4;\n" +
+				"CREATE TABLE `t` (\n`id` INT (11) NOT NULL\n);\n",
+		},
+		{
+			name:    "compared with the current schema",
+			before:  "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			after:   "CREATE DATABASE `d` DEFAULT CHARACTER SET utf8mb4;",
+			current: "CREATE DATABASE IF NOT EXISTS `d` DEFAULT CHARACTER SET = latin1 DEFAULT COLLATE = latin1_swedish_ci;",
+			want:    "ALTER DATABASE `d` CHARACTER SET = utf8mb4;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := diff.Strings(&buf, tt.before, tt.after, diff.WithCurrentSchema(tt.current)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("mismatch (-want/+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffQualifiedTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		database string
+		before   string
+		after    string
+		want     string
+	}{
+		{
+			name:   "create table in another database",
+			before: "",
+			after:  "CREATE TABLE `other`.`hoge` ( `id` INT NOT NULL );",
+			want:   "CREATE TABLE `other`.`hoge` (\n`id` INT (11) NOT NULL\n);\n",
+		},
+		{
+			name:   "drop table in another database",
+			before: "USE other; CREATE TABLE `hoge` ( `id` INT NOT NULL );",
+			after:  "",
+			want:   "DROP TABLE `other`.`hoge`;\n",
+		},
+		{
+			name:   "same name in different databases",
+			before: "CREATE TABLE `a`.`hoge` ( `id` INT NOT NULL );",
+			after:  "CREATE TABLE `a`.`hoge` ( `id` INT NOT NULL ); CREATE TABLE `b`.`hoge` ( `id` INT NOT NULL );",
+			want:   "CREATE TABLE `b`.`hoge` (\n`id` INT (11) NOT NULL\n);\n",
+		},
+		{
+			name:   "alter table in another database",
+			before: "USE other; CREATE TABLE `hoge` ( `id` INT NOT NULL );",
+			after:  "CREATE TABLE `other`.`hoge` ( `id` INT NOT NULL, `a` INT NOT NULL );",
+			want:   "ALTER TABLE `other`.`hoge` ADD COLUMN `a` INT (11) NOT NULL AFTER `id`;\n",
+		},
+		{
+			name:   "add cross-database foreign key",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL );",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL, CONSTRAINT `fk` FOREIGN KEY (`id`) REFERENCES `other`.`fuga` (`id`) );",
+			want:   "ALTER TABLE `hoge` ADD INDEX `fk` (`id`), ADD CONSTRAINT `fk` FOREIGN KEY (`id`) REFERENCES `other`.`fuga` (`id`);\n",
+		},
+		{
+			name:   "rename table in another database",
+			before: "CREATE TABLE `other`.`hoge` ( `id` INT NOT NULL );",
+			after:  "-- schemalex:renamed-from hoge\nCREATE TABLE `other`.`fuga` ( `id` INT NOT NULL );",
+			want:   "RENAME TABLE `other`.`hoge` TO `other`.`fuga`;\n",
+		},
+		{
+			name:     "tables in the current database",
+			database: "gotest",
+			before:   "CREATE TABLE `hoge` ( `id` INT NOT NULL, FOREIGN KEY (`id`) REFERENCES `fuga` (`id`) );",
+			after:    "USE gotest; CREATE TABLE `hoge` ( `id` INT NOT NULL, FOREIGN KEY (`id`) REFERENCES `GOTEST`.`fuga` (`id`) );",
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := diff.Strings(&buf, tt.before, tt.after, diff.WithDatabase(tt.database)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("mismatch (-want/+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestImpact(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []diff.Impact
+	}{
+		{
+			name:   "create table",
+			before: "",
+			after:  "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "drop table",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+			after:  "",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "add column",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "drop column",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "change comment",
+			before: "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL COMMENT 'foo' )",
+			after:  "CREATE TABLE `hoge` ( `id` INTEGER NOT NULL COMMENT 'bar' )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "make index invisible",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL, INDEX `idx` (`id`) )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL INVISIBLE, INDEX `idx` (`id`) INVISIBLE )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "add range partition",
+			before: "CREATE TABLE `hoge` ( `y` INT NOT NULL ) PARTITION BY RANGE (y) (PARTITION p0 VALUES LESS THAN (2000))",
+			after:  "CREATE TABLE `hoge` ( `y` INT NOT NULL ) PARTITION BY RANGE (y) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN (2010))",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "drop range partition",
+			before: "CREATE TABLE `hoge` ( `y` INT NOT NULL ) PARTITION BY RANGE (y) (PARTITION p0 VALUES LESS THAN (2000), PARTITION p1 VALUES LESS THAN (2010))",
+			after:  "CREATE TABLE `hoge` ( `y` INT NOT NULL ) PARTITION BY RANGE (y) (PARTITION p1 VALUES LESS THAN (2010))",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "partition table",
+			before: "CREATE TABLE `hoge` ( `y` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `y` INT NOT NULL ) PARTITION BY HASH (y) PARTITIONS 4",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "widen varchar",
+			before: "CREATE TABLE `hoge` ( `id` VARCHAR(20) NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` VARCHAR(255) NOT NULL )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "narrow varchar",
+			before: "CREATE TABLE `hoge` ( `id` VARCHAR(255) NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` VARCHAR(20) NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "varchar to text",
+			before: "CREATE TABLE `hoge` ( `id` VARCHAR(255) NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` TEXT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "widen charset",
+			before: "CREATE TABLE `hoge` ( `id` VARCHAR(255) CHARACTER SET utf8 NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` VARCHAR(255) CHARACTER SET utf8mb4 NOT NULL )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "narrow charset",
+			before: "CREATE TABLE `hoge` ( `id` VARCHAR(255) CHARACTER SET utf8mb4 NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` VARCHAR(255) CHARACTER SET latin1 NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "null to not null",
+			before: "CREATE TABLE `hoge` ( `id` INT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "not null to null",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NULL )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "narrow integer",
+			before: "CREATE TABLE `hoge` ( `id` BIGINT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "unsigned to signed",
+			before: "CREATE TABLE `hoge` ( `id` INT UNSIGNED NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "unsigned to wider signed",
+			before: "CREATE TABLE `hoge` ( `id` INT UNSIGNED NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` BIGINT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "narrow decimal",
+			before: "CREATE TABLE `hoge` ( `id` DECIMAL(10, 2) NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` DECIMAL(10, 1) NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "remove enum value",
+			before: "CREATE TABLE `hoge` ( `id` ENUM('a', 'b') NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` ENUM('a') NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "integer to string",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` VARCHAR(255) NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "add primary key",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL, PRIMARY KEY (`id`) )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "add index",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL, INDEX `id` (`id`) )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "change engine",
+			before: "CREATE TABLE `hoge` ( `id` INT NOT NULL ) ENGINE = MyISAM",
+			after:  "CREATE TABLE `hoge` ( `id` INT NOT NULL ) ENGINE = InnoDB",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "rename column",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( -- schemalex:renamed-from a\n`b` INT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "add check constraint",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, CHECK (a > 0) )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "add check constraint not enforced",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, CHECK (a > 0) NOT ENFORCED )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "drop virtual generated column",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT AS (a + 1) VIRTUAL )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL )",
+			want:   []diff.Impact{diff.ImpactSafe},
+		},
+		{
+			name:   "virtual generated column to stored",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT AS (a + 1) VIRTUAL )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT AS (a + 1) STORED )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "stored generated column to column",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT AS (a * 2) STORED )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT )",
+			want:   []diff.Impact{diff.ImpactLockHeavy},
+		},
+		{
+			name:   "virtual generated column to column",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT AS (a * 2) VIRTUAL )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+		{
+			name:   "column to generated column",
+			before: "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT NOT NULL )",
+			after:  "CREATE TABLE `hoge` ( `a` INT NOT NULL, `b` INT AS (a + 1) STORED NOT NULL )",
+			want:   []diff.Impact{diff.ImpactDataLoss},
+		},
+	}
+
+	p := schemalex.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, err := p.ParseString(tt.before)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, err := p.ParseString(tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmts, err := diff.Diff(before, after)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]diff.Impact, 0, len(stmts))
+			for _, stmt := range stmts {
+				got = append(got, stmt.Impact())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("impact mismatch (-want/+got)\n%s\nstmts = %v", diff, stmts)
+			}
+		})
+	}
+}
+
+func TestStmt(t *testing.T) {
+	p := schemalex.New()
+	before, err := p.ParseString("CREATE TABLE `hoge` ( `id` INTEGER NOT NULL, `a` INTEGER NOT NULL, INDEX `a` (`a`) );\n" +
+		"CREATE TABLE `fuga` ( `id` INTEGER NOT NULL )")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := p.ParseString("CREATE TABLE `hoge` ( `id` BIGINT NOT NULL, `b` INTEGER NOT NULL ) COMMENT 'hoge';\n" +
+		"CREATE TABLE `piyo` ( `id` INTEGER NOT NULL )")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := diff.Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type clause struct {
+		Kind   diff.ClauseKind
+		SQL    string
+		Before string
+		After  string
+	}
+	type stmt struct {
+		Kind    diff.StmtKind
+		Table   string
+		Before  string
+		After   string
+		Clauses []clause
+	}
+	id := func(s model.Stmt) string {
+		if s == nil {
+			return ""
+		}
+		return s.ID()
+	}
+	got := []stmt{}
+	for _, s := range stmts {
+		var clauses []clause
+		for _, c := range s.Clauses() {
+			clauses = append(clauses, clause{
+				Kind:   c.Kind(),
+				SQL:    c.String(),
+				Before: id(c.Before()),
+				After:  id(c.After()),
+			})
+		}
+		got = append(got, stmt{
+			Kind:    s.Kind(),
+			Table:   s.Table(),
+			Before:  id(s.Before()),
+			After:   id(s.After()),
+			Clauses: clauses,
+		})
+	}
+
+	idx := before[0].(*model.Table).Indexes[0]
+	want := []stmt{
+		{
+			Kind:   diff.StmtKindDropTable,
+			Table:  "table#fuga",
+			Before: "table#fuga",
+		},
+		{
+			Kind:  diff.StmtKindCreateTable,
+			Table: "table#piyo",
+			After: "table#piyo",
+		},
+		{
+			Kind:   diff.StmtKindAlterTable,
+			Table:  "table#hoge",
+			Before: "table#hoge",
+			After:  "table#hoge",
+			Clauses: []clause{
+				{
+					Kind:   diff.ClauseKindDropIndex,
+					SQL:    "DROP INDEX `a`",
+					Before: idx.ID(),
+				},
+				{
+					Kind:   diff.ClauseKindDropColumn,
+					SQL:    "DROP COLUMN `a`",
+					Before: "tablecol#a",
+				},
+				{
+					Kind:  diff.ClauseKindAddColumn,
+					SQL:   "ADD COLUMN `b` INT (11) NOT NULL AFTER `id`",
+					After: "tablecol#b",
+				},
+				{
+					Kind:   diff.ClauseKindChangeColumn,
+					SQL:    "CHANGE COLUMN `id` `id` BIGINT (20) NOT NULL",
+					Before: "tablecol#id",
+					After:  "tablecol#id",
+				},
+				{
+					Kind:  diff.ClauseKindTableOption,
+					SQL:   "COMMENT = 'hoge'",
+					After: "tableopt#comment",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("stmt mismatch (-want/+got)\n%s", diff)
+	}
+}
+
 func TestDiff_Integrated(t *testing.T) {
 	database.SkipIfNoTestDatabase(t)
 
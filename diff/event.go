@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/format"
+	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// dropEvents drops the events that are removed.
+// Events run on schedule, so they are dropped before changing tables.
+func (ctx *diffCtx) dropEvents() error {
+	ids := ctx.fromSet.Difference(ctx.toSet)
+	for _, id := range ids.ToSlice() {
+		stmt, ok := ctx.from.Lookup(id)
+		if !ok {
+			return fmt.Errorf("failed to lookup event: %q", id)
+		}
+		event, ok := stmt.(*model.Event)
+		if !ok {
+			continue
+		}
+		ctx.append(Stmt{
+			sql:    "DROP EVENT " + event.Name.Quoted(),
+			kind:   StmtKindDropEvent,
+			table:  id,
+			before: event,
+		})
+	}
+	return nil
+}
+
+// createEvents creates the events that are added, and alters the events that are changed.
+// Events depend on tables, so they are created after changing tables.
+func (ctx *diffCtx) createEvents() error {
+	var buf bytes.Buffer
+	for _, stmt := range ctx.to {
+		event, ok := stmt.(*model.Event)
+		if !ok {
+			continue
+		}
+
+		if stmt, ok := ctx.from.Lookup(event.ID()); ok {
+			if e, ok := stmt.(*model.Event); ok {
+				if sql, ok := ctx.alterEvent(e, event); ok {
+					ctx.append(Stmt{
+						sql:    sql,
+						kind:   StmtKindAlterEvent,
+						table:  event.ID(),
+						before: e,
+						after:  event,
+					})
+				}
+				continue
+			}
+		}
+
+		e := *event
+		e.IfNotExists = false
+		buf.Reset()
+		if err := format.SQL(&buf, &e, formatOptions(ctx.target)...); err != nil {
+			return fmt.Errorf("failed to format a statement: %w", err)
+		}
+		ctx.append(Stmt{
+			sql:   buf.String(),
+			kind:  StmtKindCreateEvent,
+			table: event.ID(),
+			after: event,
+		})
+	}
+	return nil
+}
+
+// alterEvent returns the ALTER EVENT statement that changes the event from before to after.
+// It returns false if nothing is changed.
+func (ctx *diffCtx) alterEvent(before, after *model.Event) (string, bool) {
+	var buf strings.Builder
+	var changed bool
+	buf.WriteString("ALTER")
+	if !equalDefiner(before.Definer, after.Definer) {
+		buf.WriteString(" DEFINER = ")
+		buf.WriteString(after.Definer)
+		changed = true
+	}
+	buf.WriteString(" EVENT ")
+	buf.WriteString(after.Name.Quoted())
+
+	if !equalSchedule(before.Schedule, after.Schedule) {
+		buf.WriteString(" ON SCHEDULE ")
+		buf.WriteString(after.Schedule)
+		changed = true
+	}
+	if before.Preserve != after.Preserve {
+		if after.Preserve {
+			buf.WriteString(" ON COMPLETION PRESERVE")
+		} else {
+			buf.WriteString(" ON COMPLETION NOT PRESERVE")
+		}
+		changed = true
+	}
+	if status := viewOrDefault(after.Status, "ENABLE"); !strings.EqualFold(viewOrDefault(before.Status, "ENABLE"), status) {
+		if status == "DISABLE ON REPLICA" && (ctx.target == nil || !ctx.target.SupportsReplicaKeyword()) {
+			// the old servers only accept SLAVE.
+			status = "DISABLE ON SLAVE"
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(status)
+		changed = true
+	}
+	if before.Comment.Value != after.Comment.Value {
+		buf.WriteString(" COMMENT '")
+		buf.WriteString(strings.ReplaceAll(after.Comment.Value, "'", "''"))
+		buf.WriteByte('\'')
+		changed = true
+	}
+	if util.NormalizeSQL(before.Body) != util.NormalizeSQL(after.Body) {
+		buf.WriteString("\nDO ")
+		buf.WriteString(after.Body)
+		changed = true
+	}
+	return buf.String(), changed
+}
+
+// equalSchedule returns whether schedule a and b are same.
+// MySQL fills the omitted STARTS with the time when the event is created,
+// so STARTS is compared only if both are specified.
+func equalSchedule(a, b string) bool {
+	a, b = util.NormalizeSQL(a), util.NormalizeSQL(b)
+	if !strings.Contains(a, " starts ") || !strings.Contains(b, " starts ") {
+		a, b = trimScheduleStarts(a), trimScheduleStarts(b)
+	}
+	return a == b
+}
+
+// trimScheduleStarts removes STARTS from the normalized schedule.
+func trimScheduleStarts(s string) string {
+	i := strings.Index(s, " starts ")
+	if i < 0 {
+		return s
+	}
+	if j := strings.Index(s[i:], " ends "); j >= 0 {
+		return s[:i] + s[i+j:]
+	}
+	return s[:i]
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// needsRecreateColumn returns whether the column before must be dropped and added again
+// to change it into the column after.
+// MySQL can't change VIRTUAL generated columns into STORED ones or non-generated ones by ALTER TABLE ... CHANGE COLUMN,
+// and vice versa. STORED generated columns and non-generated columns can be converted in place.
+func needsRecreateColumn(before, after *model.TableColumn) bool {
+	b, a := generatedStorage(before), generatedStorage(after)
+	if b == a {
+		return false
+	}
+	return b == model.GeneratedStorageVirtual || a == model.GeneratedStorageVirtual
+}
+
+// generatedStorage returns the storage kind of the column.
+// It returns GeneratedStorageNone if the column is not a generated column.
+func generatedStorage(col *model.TableColumn) model.GeneratedStorage {
+	if !col.Generated.Valid {
+		return model.GeneratedStorageNone
+	}
+	if col.GeneratedStorage == model.GeneratedStorageNone {
+		return model.GeneratedStorageVirtual
+	}
+	return col.GeneratedStorage
+}
+
+// equalExpr returns whether the expressions a and b are same,
+// ignoring the differences of white spaces, cases, quotes of identifiers and redundant parentheses.
+// e.g. SHOW CREATE TABLE shows `a + b` as ((`a` + `b`)).
+func equalExpr(a, b string) bool {
+	return trimParens(util.NormalizeSQL(a)) == trimParens(util.NormalizeSQL(b))
+}
+
+// trimParens removes the parentheses that surround the whole expression.
+func trimParens(expr string) string {
+	for len(expr) >= 2 && expr[0] == '(' && expr[len(expr)-1] == ')' && closingParen(expr) == len(expr)-1 {
+		expr = expr[1 : len(expr)-1]
+	}
+	return expr
+}
+
+// closingParen returns the position of the parenthesis that closes the first one in expr.
+// It returns -1 if the parentheses are not balanced.
+func closingParen(expr string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
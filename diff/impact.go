@@ -0,0 +1,298 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// columnImpact returns the impact of changing the column from before to after.
+func columnImpact(before, after *model.TableColumn) Impact {
+	if after.Generated.Valid {
+		// the values of the column are replaced with the generated values.
+		if !before.Generated.Valid {
+			return ImpactDataLoss
+		}
+		if equalColumn(before, after) {
+			return ImpactSafe
+		}
+		return ImpactLockHeavy
+	}
+	if !coversColumn(before, after) {
+		return ImpactDataLoss
+	}
+
+	// changing the name, the comment, the default value or the visibility only updates the metadata.
+	// the others may rebuild the table.
+	b, a := *before, *after
+	b.Name, a.Name = "", ""
+	b.Invisible, a.Invisible = false, false
+	b.Comment, a.Comment = model.MaybeString{}, model.MaybeString{}
+	b.Default, a.Default = model.DefaultValue{}, model.DefaultValue{}
+	b.RenamedFrom, a.RenamedFrom = model.MaybeIdent{}, model.MaybeIdent{}
+	if !equalColumn(&b, &a) {
+		return ImpactLockHeavy
+	}
+	return ImpactSafe
+}
+
+// dropColumnImpact returns the impact of dropping the column.
+func dropColumnImpact(col *model.TableColumn) Impact {
+	switch generatedStorage(col) {
+	case model.GeneratedStorageVirtual:
+		// the values are not stored, so nothing is lost.
+		return ImpactSafe
+	case model.GeneratedStorageStored:
+		// the values can be generated again, but dropping it rebuilds the table.
+		return ImpactLockHeavy
+	}
+	return ImpactDataLoss
+}
+
+// addColumnImpact returns the impact of adding the column.
+func addColumnImpact(col *model.TableColumn) Impact {
+	if generatedStorage(col) == model.GeneratedStorageStored {
+		// all the values are generated and stored.
+		return ImpactLockHeavy
+	}
+	return ImpactSafe
+}
+
+// coversColumn returns whether the column after can store all values of the column before.
+func coversColumn(before, after *model.TableColumn) bool {
+	// the NULL values can't be stored, and they are converted into the implicit default values.
+	if before.NullState != model.NullStateNotNull && after.NullState == model.NullStateNotNull {
+		return false
+	}
+
+	bt, at := before.Type.SynonymType(), after.Type.SynonymType()
+
+	switch {
+	case isIntegerType(bt):
+		if !isIntegerType(at) {
+			return false
+		}
+		bb, ab := integerBits(bt), integerBits(at)
+		if before.Unsigned {
+			if after.Unsigned {
+				return ab >= bb
+			}
+			return ab > bb
+		}
+		return !after.Unsigned && ab >= bb
+
+	case bt == model.ColumnTypeBit:
+		return at == model.ColumnTypeBit && lengthOf(after, 1) >= lengthOf(before, 1)
+
+	case isCharType(bt):
+		if !isCharType(at) {
+			return false
+		}
+		if !coversCharset(charsetOf(before), charsetOf(after)) {
+			return false
+		}
+		return charCapacity(after) >= charCapacity(before)
+
+	case isBinaryType(bt):
+		if !isBinaryType(at) {
+			return false
+		}
+		return binaryCapacity(after) >= binaryCapacity(before)
+
+	case bt == model.ColumnTypeDecimal:
+		if at != model.ColumnTypeDecimal {
+			return false
+		}
+		bp, bs := decimalPrecision(before)
+		ap, as := decimalPrecision(after)
+		return as >= bs && ap-as >= bp-bs
+
+	case bt == model.ColumnTypeFloat:
+		return at == model.ColumnTypeFloat || at == model.ColumnTypeDouble
+
+	case bt == model.ColumnTypeDouble:
+		return at == model.ColumnTypeDouble
+
+	case bt == model.ColumnTypeDate:
+		return at == model.ColumnTypeDate || at == model.ColumnTypeDateTime
+
+	case bt == model.ColumnTypeDateTime, bt == model.ColumnTypeTimestamp, bt == model.ColumnTypeTime:
+		if at != bt && !(bt == model.ColumnTypeTimestamp && at == model.ColumnTypeDateTime) {
+			return false
+		}
+		return lengthOf(after, 0) >= lengthOf(before, 0)
+
+	case bt == model.ColumnTypeEnum:
+		return at == model.ColumnTypeEnum && containsAll(after.EnumValues, before.EnumValues)
+
+	case bt == model.ColumnTypeSet:
+		return at == model.ColumnTypeSet && containsAll(after.SetValues, before.SetValues)
+	}
+
+	// JSON, YEAR, spatial types, etc.
+	return at == bt
+}
+
+func isIntegerType(t model.ColumnType) bool {
+	return integerBits(t) > 0
+}
+
+// integerBits returns the storage size of the integer type in bits.
+func integerBits(t model.ColumnType) int {
+	switch t {
+	case model.ColumnTypeTinyInt:
+		return 8
+	case model.ColumnTypeSmallInt:
+		return 16
+	case model.ColumnTypeMediumInt:
+		return 24
+	case model.ColumnTypeInt:
+		return 32
+	case model.ColumnTypeBigInt:
+		return 64
+	}
+	return 0
+}
+
+func isCharType(t model.ColumnType) bool {
+	switch t {
+	case model.ColumnTypeChar, model.ColumnTypeVarChar,
+		model.ColumnTypeTinyText, model.ColumnTypeText, model.ColumnTypeMediumText, model.ColumnTypeLongText:
+		return true
+	}
+	return false
+}
+
+// charsetOf returns the character set of the string column.
+// It returns an empty string if the column inherits the character set of the table.
+func charsetOf(col *model.TableColumn) string {
+	var charset string
+	switch {
+	case col.CharacterSet.Valid:
+		charset = strings.ToLower(string(col.CharacterSet.Ident))
+	case col.Collation.Valid:
+		// the name of the collation starts with its character set.
+		charset, _, _ = strings.Cut(strings.ToLower(string(col.Collation.Ident)), "_")
+	}
+	if charset == "utf8" {
+		return "utf8mb3"
+	}
+	return charset
+}
+
+// coversCharset returns whether the character set after can encode all characters of the character set before.
+// The character sets inherited from the table are unknown, so they are regarded as covering.
+func coversCharset(before, after string) bool {
+	if before == "" || after == "" || before == after {
+		return true
+	}
+	switch after {
+	case "utf8mb4", "utf16", "utf16le", "utf32", "gb18030":
+		// they encode all Unicode characters.
+		return before != "binary"
+	case "utf8mb3", "ucs2":
+		// they encode the characters in the Basic Multilingual Plane.
+		switch before {
+		case "utf8mb4", "utf16", "utf16le", "utf32", "gb18030", "binary":
+			return false
+		}
+		return true
+	}
+	return before == "ascii"
+}
+
+// charCapacity returns the maximum length of the string column.
+func charCapacity(col *model.TableColumn) int64 {
+	switch col.Type {
+	case model.ColumnTypeChar:
+		return lengthOf(col, 1)
+	case model.ColumnTypeVarChar:
+		return lengthOf(col, 0)
+	}
+	return lobCapacity(col.Type)
+}
+
+func isBinaryType(t model.ColumnType) bool {
+	switch t {
+	case model.ColumnTypeBinary, model.ColumnTypeVarBinary,
+		model.ColumnTypeTinyBlob, model.ColumnTypeBlob, model.ColumnTypeMediumBlob, model.ColumnTypeLongBlob:
+		return true
+	}
+	return false
+}
+
+// binaryCapacity returns the maximum length of the binary column.
+func binaryCapacity(col *model.TableColumn) int64 {
+	switch col.Type {
+	case model.ColumnTypeBinary:
+		return lengthOf(col, 1)
+	case model.ColumnTypeVarBinary:
+		return lengthOf(col, 0)
+	}
+	return lobCapacity(col.Type)
+}
+
+// lobCapacity returns the maximum length of TEXT and BLOB types.
+func lobCapacity(t model.ColumnType) int64 {
+	switch t {
+	case model.ColumnTypeTinyText, model.ColumnTypeTinyBlob:
+		return 1<<8 - 1
+	case model.ColumnTypeText, model.ColumnTypeBlob:
+		return 1<<16 - 1
+	case model.ColumnTypeMediumText, model.ColumnTypeMediumBlob:
+		return 1<<24 - 1
+	case model.ColumnTypeLongText, model.ColumnTypeLongBlob:
+		return 1<<32 - 1
+	}
+	return 0
+}
+
+// decimalPrecision returns the precision and the scale of the DECIMAL column.
+func decimalPrecision(col *model.TableColumn) (int64, int64) {
+	precision := lengthOf(col, 10)
+	var scale int64
+	if col.Length != nil && col.Length.Decimals.Valid {
+		if v, err := strconv.ParseInt(col.Length.Decimals.Value, 10, 64); err == nil {
+			scale = v
+		}
+	}
+	return precision, scale
+}
+
+// lengthOf returns the length of the column.
+// If the length is not specified, it returns defaultLength.
+func lengthOf(col *model.TableColumn, defaultLength int64) int64 {
+	if col.Length == nil || col.Length.Length == "" {
+		return defaultLength
+	}
+	v, err := strconv.ParseInt(col.Length.Length, 10, 64)
+	if err != nil {
+		return defaultLength
+	}
+	return v
+}
+
+// containsAll returns whether values contains all elements of subset.
+func containsAll(values, subset []string) bool {
+	m := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		m[strings.ToLower(v)] = struct{}{}
+	}
+	for _, v := range subset {
+		if _, ok := m[strings.ToLower(v)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tableOptionImpact returns the impact of changing the table option.
+func tableOptionImpact(opt *model.TableOption) Impact {
+	switch opt.ID() {
+	case "tableopt#engine", "tableopt#row_format", "tableopt#key_block_size", "tableopt#tablespace":
+		// these options rebuild the table.
+		return ImpactLockHeavy
+	}
+	return ImpactSafe
+}
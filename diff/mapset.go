@@ -13,6 +13,11 @@ func (s set) Add(item string) {
 	s[item] = struct{}{}
 }
 
+func (s set) Contains(item string) bool {
+	_, ok := s[item]
+	return ok
+}
+
 func (s set) Difference(t set) set {
 	u := newSet()
 	for item := range s {
@@ -23,6 +28,17 @@ func (s set) Difference(t set) set {
 	return u
 }
 
+func (s set) Union(t set) set {
+	u := newSet()
+	for item := range s {
+		u[item] = struct{}{}
+	}
+	for item := range t {
+		u[item] = struct{}{}
+	}
+	return u
+}
+
 func (s set) Intersect(t set) set {
 	u := newSet()
 	if len(s) < len(t) {
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/model"
 )
 
 type myOptions struct {
@@ -11,6 +12,8 @@ type myOptions struct {
 	transaction   bool
 	currentSchema string
 	indent        string
+	version       *model.ServerVersion
+	database      string
 }
 
 type Option interface {
@@ -73,3 +76,31 @@ func WithIndent(s string, n int) Option {
 	}
 	return withIndent(strings.Repeat(s, n))
 }
+
+type withServerVersion model.ServerVersion
+
+func (opt withServerVersion) apply(opts *myOptions) {
+	v := model.ServerVersion(opt)
+	opts.version = &v
+}
+
+// WithServerVersion specifies the version of the database server.
+// The columns that have the same definition on the server are treated as equal,
+// even if they are described differently, e.g. INT(11) and INT on MySQL 8.0.19 and later.
+// If unspecified, model.DefaultServerVersion will be used.
+func WithServerVersion(v model.ServerVersion) Option {
+	return withServerVersion(v)
+}
+
+type withDatabase string
+
+func (opt withDatabase) apply(opts *myOptions) {
+	opts.database = string(opt)
+}
+
+// WithDatabase specifies the name of the database that the statements are run on.
+// The tables qualified with the name are treated as the tables in the current database,
+// so the schema that selects the database by USE can be compared with the one that doesn't.
+func WithDatabase(name string) Option {
+	return withDatabase(name)
+}
@@ -0,0 +1,311 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/format"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// MySQL can't combine the partition operations with the other alter specifications,
+// so each of them is generated as a separate ALTER TABLE statement.
+
+// newPartitionCtx returns a new alterCtx for a partition operation of the table.
+func (ctx *alterCtx) newPartitionCtx() *alterCtx {
+	return &alterCtx{
+		from:     ctx.from,
+		to:       ctx.to,
+		cur:      ctx.cur,
+		original: ctx.original,
+		version:  ctx.version,
+		target:   ctx.target,
+	}
+}
+
+// removePartitioning removes the partitioning if the new table isn't partitioned.
+// It is executed before the other alter specifications,
+// because the unique keys of the partitioned tables must include the partitioning columns.
+func (ctx *alterCtx) removePartitioning() (Stmt, bool) {
+	if ctx.from.Partition == nil || ctx.to.Partition != nil {
+		return Stmt{}, false
+	}
+
+	pctx := ctx.newPartitionCtx()
+	pctx.begin(ClauseKindRemovePartitioning, ctx.from.Partition, nil)
+	pctx.raise(ImpactLockHeavy)
+	pctx.writeString("REMOVE PARTITIONING")
+	return pctx.stmt(), true
+}
+
+// alterPartitions changes the partitioning.
+// The partitions are added, dropped and reorganized if possible,
+// otherwise the table is repartitioned by PARTITION BY.
+func (ctx *alterCtx) alterPartitions() ([]Stmt, error) {
+	from, to := ctx.from.Partition, ctx.to.Partition
+	if to == nil {
+		return nil, nil
+	}
+	if from == nil || !equalPartitionScheme(from, to) {
+		return ctx.partitionBy()
+	}
+
+	switch to.Type {
+	case model.PartitionTypeHash, model.PartitionTypeKey:
+		if len(from.Definitions) > 0 || len(to.Definitions) > 0 {
+			if equalPartitionDefinitions(from.Definitions, to.Definitions) {
+				return nil, nil
+			}
+			return ctx.partitionBy()
+		}
+		return ctx.resizePartitions()
+	}
+	return ctx.alterPartitionDefinitions()
+}
+
+// partitionBy repartitions the table.
+func (ctx *alterCtx) partitionBy() ([]Stmt, error) {
+	pctx := ctx.newPartitionCtx()
+	if ctx.from.Partition == nil {
+		pctx.begin(ClauseKindPartitionBy, nil, ctx.to.Partition)
+	} else {
+		pctx.begin(ClauseKindPartitionBy, ctx.from.Partition, ctx.to.Partition)
+	}
+	pctx.raise(ImpactLockHeavy)
+
+	// write the definitions in a line, like the other alter specifications.
+	part := *ctx.to.Partition
+	part.Definitions = nil
+	if err := format.SQL(&pctx.buf, &part); err != nil {
+		return nil, err
+	}
+	if len(ctx.to.Partition.Definitions) > 0 {
+		pctx.writeString(" (")
+		if err := pctx.writePartitionDefinitions(ctx.to.Partition.Definitions); err != nil {
+			return nil, err
+		}
+		pctx.writeString(")")
+	}
+	return []Stmt{pctx.stmt()}, nil
+}
+
+// resizePartitions changes the number of the partitions of HASH and KEY partitioning.
+func (ctx *alterCtx) resizePartitions() ([]Stmt, error) {
+	from, to := partitionNum(ctx.from.Partition), partitionNum(ctx.to.Partition)
+	if from == to {
+		return nil, nil
+	}
+
+	// the rows are redistributed to the new partitions.
+	pctx := ctx.newPartitionCtx()
+	if from < to {
+		pctx.begin(ClauseKindAddPartition, ctx.from.Partition, ctx.to.Partition)
+		pctx.writeString("ADD PARTITION PARTITIONS ")
+		pctx.writeString(strconv.Itoa(to - from))
+	} else {
+		pctx.begin(ClauseKindCoalescePartition, ctx.from.Partition, ctx.to.Partition)
+		pctx.writeString("COALESCE PARTITION ")
+		pctx.writeString(strconv.Itoa(from - to))
+	}
+	pctx.raise(ImpactLockHeavy)
+	return []Stmt{pctx.stmt()}, nil
+}
+
+// partitionNum returns the number of the partitions of HASH and KEY partitioning.
+func partitionNum(part *model.Partition) int {
+	if !part.Num.Valid {
+		return 1
+	}
+	n, err := strconv.Atoi(part.Num.Value)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// alterPartitionDefinitions changes the partitions of RANGE and LIST partitioning.
+// The partitions that are removed are dropped first, and then the new partitions are added.
+// The partitions in the middle and the changed ones are reorganized.
+func (ctx *alterCtx) alterPartitionDefinitions() ([]Stmt, error) {
+	var stmts []Stmt
+	from, to := ctx.from.Partition, ctx.to.Partition
+
+	// drop the removed partitions
+	var dropped []model.Ident
+	var remaining []*model.PartitionDefinition
+	for _, def := range from.Definitions {
+		if _, ok := to.LookupDefinition(def.ID()); ok {
+			remaining = append(remaining, def)
+		} else {
+			dropped = append(dropped, def.Name)
+		}
+	}
+	if len(dropped) > 0 {
+		pctx := ctx.newPartitionCtx()
+		pctx.begin(ClauseKindDropPartition, from, to)
+		pctx.raise(ImpactDataLoss)
+		pctx.writeString("DROP PARTITION ")
+		pctx.writeIdents(dropped)
+		stmts = append(stmts, pctx.stmt())
+	}
+
+	// find the partitions that differ
+	prefix := 0
+	for prefix < len(remaining) && prefix < len(to.Definitions) &&
+		equalPartitionDefinition(remaining[prefix], to.Definitions[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for prefix+suffix < len(remaining) && prefix+suffix < len(to.Definitions) &&
+		equalPartitionDefinition(remaining[len(remaining)-suffix-1], to.Definitions[len(to.Definitions)-suffix-1]) {
+		suffix++
+	}
+	fromDefs := remaining[prefix : len(remaining)-suffix]
+	toDefs := to.Definitions[prefix : len(to.Definitions)-suffix]
+	if len(toDefs) == 0 {
+		return stmts, nil
+	}
+
+	isRange := to.Type == model.PartitionTypeRange || to.Type == model.PartitionTypeRangeColumns
+	if len(fromDefs) == 0 && (!isRange || suffix == 0) {
+		// the new partitions can be added, because they follow the last one of RANGE partitioning,
+		// or the order of LIST partitioning doesn't matter.
+		pctx := ctx.newPartitionCtx()
+		pctx.begin(ClauseKindAddPartition, from, to)
+		pctx.writeString("ADD PARTITION (")
+		if err := pctx.writePartitionDefinitions(toDefs); err != nil {
+			return nil, err
+		}
+		pctx.writeString(")")
+		return append(stmts, pctx.stmt()), nil
+	}
+
+	if isRange && suffix > 0 && (len(fromDefs) == 0 || !equalExpr(fromDefs[len(fromDefs)-1].Values.Value, toDefs[len(toDefs)-1].Values.Value)) {
+		// MySQL can't change the range that the reorganized partitions cover, except for the last partition.
+		// reorganize the next partition together.
+		fromDefs = remaining[prefix : len(remaining)-suffix+1]
+		toDefs = to.Definitions[prefix : len(to.Definitions)-suffix+1]
+	}
+
+	// the rows are moved to the new partitions.
+	pctx := ctx.newPartitionCtx()
+	pctx.begin(ClauseKindReorganizePartition, from, to)
+	pctx.raise(ImpactLockHeavy)
+	pctx.writeString("REORGANIZE PARTITION ")
+	names := make([]model.Ident, 0, len(fromDefs))
+	for _, def := range fromDefs {
+		names = append(names, def.Name)
+	}
+	pctx.writeIdents(names)
+	pctx.writeString(" INTO (")
+	if err := pctx.writePartitionDefinitions(toDefs); err != nil {
+		return nil, err
+	}
+	pctx.writeString(")")
+	return append(stmts, pctx.stmt()), nil
+}
+
+func (ctx *alterCtx) writeIdents(idents []model.Ident) {
+	for i, ident := range idents {
+		if i > 0 {
+			ctx.writeString(", ")
+		}
+		ctx.writeIdent(ident)
+	}
+}
+
+func (ctx *alterCtx) writePartitionDefinitions(defs []*model.PartitionDefinition) error {
+	for i, def := range defs {
+		if i > 0 {
+			ctx.writeString(", ")
+		}
+		if err := format.SQL(&ctx.buf, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// equalPartitionScheme returns whether partitioning a and b have same type and same key,
+// excluding the partitions.
+func equalPartitionScheme(a, b *model.Partition) bool {
+	if a.Type != b.Type || a.Linear != b.Linear {
+		return false
+	}
+	if !equalExpr(a.Expr, b.Expr) {
+		return false
+	}
+	if len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i := range a.Columns {
+		if !strings.EqualFold(string(a.Columns[i]), string(b.Columns[i])) {
+			return false
+		}
+	}
+	if a.Algorithm != b.Algorithm {
+		return false
+	}
+
+	// changing the subpartitioning requires repartitioning.
+	if (a.SubPartition != nil) != (b.SubPartition != nil) {
+		return false
+	}
+	if a.SubPartition != nil {
+		if !equalPartitionScheme(a.SubPartition, b.SubPartition) || partitionNum(a.SubPartition) != partitionNum(b.SubPartition) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPartitionDefinitions(a, b []*model.PartitionDefinition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalPartitionDefinition(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalPartitionDefinition returns whether partition a and b have same name, same values and same options.
+// ENGINE is ignored, because all partitions use the engine of the table,
+// and SHOW CREATE TABLE shows it for each partition.
+func equalPartitionDefinition(a, b *model.PartitionDefinition) bool {
+	if a.ID() != b.ID() {
+		return false
+	}
+	if a.Values.Valid != b.Values.Valid || !equalExpr(a.Values.Value, b.Values.Value) {
+		return false
+	}
+	if !equalPartitionOptions(a.Options, b.Options) {
+		return false
+	}
+	return equalPartitionDefinitions(a.SubPartitions, b.SubPartitions)
+}
+
+func equalPartitionOptions(a, b []*model.TableOption) bool {
+	options := func(opts []*model.TableOption) map[string]*model.TableOption {
+		ret := make(map[string]*model.TableOption, len(opts))
+		for _, opt := range opts {
+			if id := opt.ID(); id != "tableopt#engine" {
+				ret[id] = opt
+			}
+		}
+		return ret
+	}
+	aa, bb := options(a), options(b)
+	if len(aa) != len(bb) {
+		return false
+	}
+	for id, opt := range aa {
+		other, ok := bb[id]
+		if !ok || !equalTableOption(opt, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,303 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// tableRename describes a table renamed by the renamed-from annotation.
+type tableRename struct {
+	from *model.Table
+	to   *model.Table
+}
+
+// findTableRenames finds the tables that are renamed from the tables in the old schema.
+// A table is renamed only when the old name disappears and the new name appears.
+func findTableRenames(from, to model.Stmts) []*tableRename {
+	fromTables := make(map[string]*model.Table)
+	for _, stmt := range from {
+		if table, ok := stmt.(*model.Table); ok {
+			fromTables[table.ID()] = table
+		}
+	}
+	toTables := make(map[string]*model.Table)
+	for _, stmt := range to {
+		if table, ok := stmt.(*model.Table); ok {
+			toTables[table.ID()] = table
+		}
+	}
+
+	var renames []*tableRename
+	used := newSet()
+	for _, stmt := range to {
+		table, ok := stmt.(*model.Table)
+		if !ok || !table.RenamedFrom.Valid {
+			continue
+		}
+		oldID := tableID(table.Schema, table.RenamedFrom.Ident)
+		if _, ok := fromTables[table.ID()]; ok {
+			// the table already exists.
+			continue
+		}
+		if _, ok := toTables[oldID]; ok {
+			// the old table is still alive.
+			continue
+		}
+		if _, ok := used[oldID]; ok {
+			// the old table is already renamed to another table.
+			continue
+		}
+		old, ok := fromTables[oldID]
+		if !ok {
+			// the old table is not found. it may be already renamed.
+			continue
+		}
+		used.Add(oldID)
+		renames = append(renames, &tableRename{
+			from: old,
+			to:   table,
+		})
+	}
+	sort.Slice(renames, func(i, j int) bool {
+		return renames[i].to.ID() < renames[j].to.ID()
+	})
+	return renames
+}
+
+// applyTableRenames returns a copy of stmts that the renames are applied.
+// The references to the renamed tables are also rewritten,
+// because MySQL updates them automatically.
+func applyTableRenames(stmts model.Stmts, renames []*tableRename) model.Stmts {
+	if len(stmts) == 0 || len(renames) == 0 {
+		return stmts
+	}
+	names := make(map[string]model.Ident, len(renames))
+	for _, r := range renames {
+		names[r.from.ID()] = r.to.Name
+	}
+
+	ret := make(model.Stmts, 0, len(stmts))
+	for _, stmt := range stmts {
+		if trigger, ok := stmt.(*model.Trigger); ok {
+			// RENAME TABLE moves the triggers to the new table.
+			if newName, ok := names[model.NewTable(trigger.Table).ID()]; ok {
+				t := *trigger
+				t.Table = newName
+				stmt = &t
+			}
+		}
+		table, ok := stmt.(*model.Table)
+		if !ok {
+			ret = append(ret, stmt)
+			continue
+		}
+		newName, renamed := names[table.ID()]
+		if !renamed && !hasReferenceTo(table, names) {
+			ret = append(ret, stmt)
+			continue
+		}
+
+		table = cloneTable(table)
+		if renamed {
+			oldName := table.Name
+			table.Name = newName
+			for _, idx := range table.Indexes {
+				idx.Table = table.ID()
+			}
+			renameChecks(table, oldName)
+		}
+		for _, idx := range table.Indexes {
+			if idx.Reference == nil {
+				continue
+			}
+			if name, ok := names[referencedTableID(table, idx.Reference)]; ok {
+				idx.Reference.TableName = name
+			}
+		}
+		ret = append(ret, table)
+	}
+	return ret
+}
+
+func hasReferenceTo(table *model.Table, names map[string]model.Ident) bool {
+	for _, idx := range table.Indexes {
+		if idx.Reference == nil {
+			continue
+		}
+		if _, ok := names[referencedTableID(table, idx.Reference)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tableID returns the ID of the table in the schema.
+func tableID(schema, name model.Ident) string {
+	table := model.NewTable(name)
+	table.Schema = schema
+	return table.ID()
+}
+
+// referencedTableID returns the ID of the table that the reference refers to.
+// The reference without the database name refers to the table in the same database.
+func referencedTableID(table *model.Table, r *model.Reference) string {
+	if r.TableSchema != "" {
+		return tableID(r.TableSchema, r.TableName)
+	}
+	return tableID(table.Schema, r.TableName)
+}
+
+// findColumnRenames finds the columns that are renamed from the columns in the old table.
+// It returns a map from the ID of the new column to the name of the old column.
+func findColumnRenames(from, to *model.Table) map[string]model.Ident {
+	renames := make(map[string]model.Ident)
+	used := newSet()
+	for _, col := range to.Columns {
+		if !col.RenamedFrom.Valid {
+			continue
+		}
+		oldID := model.NewTableColumn(string(col.RenamedFrom.Ident)).ID()
+		if _, ok := from.LookupColumn(col.ID()); ok {
+			// the column already exists.
+			continue
+		}
+		if _, ok := to.LookupColumn(oldID); ok {
+			// the old column is still alive.
+			continue
+		}
+		if _, ok := used[oldID]; ok {
+			// the old column is already renamed to another column.
+			continue
+		}
+		old, ok := from.LookupColumn(oldID)
+		if !ok {
+			// the old column is not found. it may be already renamed.
+			continue
+		}
+		used.Add(oldID)
+		renames[col.ID()] = old.Name
+	}
+	return renames
+}
+
+// applyColumnRenames returns a copy of the table that the renames are applied.
+// The index columns are also rewritten, because MySQL updates them automatically.
+func applyColumnRenames(table *model.Table, to *model.Table, renames map[string]model.Ident) *model.Table {
+	if len(renames) == 0 {
+		return table
+	}
+	names := make(map[string]model.Ident, len(renames))
+	for id, old := range renames {
+		col, _ := to.LookupColumn(id)
+		names[strings.ToLower(string(old))] = col.Name
+	}
+
+	table = cloneTable(table)
+	for _, col := range table.Columns {
+		if name, ok := names[strings.ToLower(string(col.Name))]; ok {
+			col.Name = name
+		}
+	}
+	for _, idx := range table.Indexes {
+		for _, col := range idx.Columns {
+			if name, ok := names[strings.ToLower(string(col.Name))]; ok {
+				col.Name = name
+			}
+		}
+	}
+	return table
+}
+
+// cloneTable returns a deep copy of the table.
+func cloneTable(table *model.Table) *model.Table {
+	ret := *table
+	ret.Columns = make([]*model.TableColumn, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		c := *col
+		ret.Columns = append(ret.Columns, &c)
+	}
+	ret.Indexes = make([]*model.Index, 0, len(table.Indexes))
+	for _, idx := range table.Indexes {
+		ret.Indexes = append(ret.Indexes, cloneIndex(idx))
+	}
+	ret.Checks = make([]*model.Check, 0, len(table.Checks))
+	for _, check := range table.Checks {
+		c := *check
+		ret.Checks = append(ret.Checks, &c)
+	}
+	ret.Options = make([]*model.TableOption, len(table.Options))
+	copy(ret.Options, table.Options)
+	return &ret
+}
+
+// cloneIndex returns a deep copy of the index.
+func cloneIndex(idx *model.Index) *model.Index {
+	ret := *idx
+	ret.Columns = make([]*model.IndexColumn, 0, len(idx.Columns))
+	for _, col := range idx.Columns {
+		c := *col
+		ret.Columns = append(ret.Columns, &c)
+	}
+	if idx.Reference != nil {
+		ref := *idx.Reference
+		ref.Columns = make([]*model.IndexColumn, 0, len(idx.Reference.Columns))
+		for _, col := range idx.Reference.Columns {
+			c := *col
+			ref.Columns = append(ref.Columns, &c)
+		}
+		ret.Reference = &ref
+	}
+	return &ret
+}
+
+// InvertRenames returns a copy of from that is annotated with the renames from `from` to `to` in reverse,
+// so that Diff(to, InvertRenames(from, to)) renames the tables and the columns back instead of dropping them.
+// The renamed-from annotations in from are discarded, because they describe the older migration.
+func InvertRenames(from, to model.Stmts) model.Stmts {
+	names := make(map[string]model.Ident)
+	for _, r := range findTableRenames(from, to) {
+		names[r.from.ID()] = r.to.Name
+	}
+	toTables := make(map[string]*model.Table)
+	for _, stmt := range to {
+		if table, ok := stmt.(*model.Table); ok {
+			toTables[table.ID()] = table
+		}
+	}
+
+	ret := make(model.Stmts, 0, len(from))
+	for _, stmt := range from {
+		table, ok := stmt.(*model.Table)
+		if !ok {
+			ret = append(ret, stmt)
+			continue
+		}
+
+		newID := table.ID()
+		table = cloneTable(table)
+		table.RenamedFrom = model.MaybeIdent{}
+		if name, ok := names[newID]; ok {
+			newID = tableID(table.Schema, name)
+			table.RenamedFrom = model.MaybeIdent{Valid: true, Ident: name}
+		}
+
+		// the map from the ID of the old column to the name of the new column.
+		columns := make(map[string]model.Ident)
+		if newTable, ok := toTables[newID]; ok {
+			for id, old := range findColumnRenames(table, newTable) {
+				col, _ := newTable.LookupColumn(id)
+				columns[model.NewTableColumn(string(old)).ID()] = col.Name
+			}
+		}
+		for _, col := range table.Columns {
+			col.RenamedFrom = model.MaybeIdent{}
+			if name, ok := columns[col.ID()]; ok {
+				col.RenamedFrom = model.MaybeIdent{Valid: true, Ident: name}
+			}
+		}
+		ret = append(ret, table)
+	}
+	return ret
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/format"
+	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// dropRoutines drops the stored procedures and functions that are removed.
+// Routines don't have any data, so they are dropped before changing tables.
+func (ctx *diffCtx) dropRoutines() error {
+	ids := ctx.fromSet.Difference(ctx.toSet)
+	for _, id := range ids.ToSlice() {
+		stmt, ok := ctx.from.Lookup(id)
+		if !ok {
+			return fmt.Errorf("failed to lookup routine: %q", id)
+		}
+		routine, ok := stmt.(*model.Routine)
+		if !ok {
+			continue
+		}
+		ctx.append(Stmt{
+			sql:    "DROP " + routine.Kind.String() + " " + routine.Name.Quoted(),
+			kind:   StmtKindDropRoutine,
+			table:  id,
+			before: routine,
+		})
+	}
+	return nil
+}
+
+// createRoutines creates the stored procedures and functions that are added or changed.
+// MySQL can't replace the body of routines, so the changed ones are dropped and created again.
+// Views may call stored functions, so they are created before creating views.
+func (ctx *diffCtx) createRoutines() error {
+	var buf bytes.Buffer
+	for _, stmt := range ctx.to {
+		routine, ok := stmt.(*model.Routine)
+		if !ok {
+			continue
+		}
+
+		if stmt, ok := ctx.from.Lookup(routine.ID()); ok {
+			if r, ok := stmt.(*model.Routine); ok {
+				if equalRoutine(r, routine) {
+					continue
+				}
+				ctx.append(Stmt{
+					sql:    "DROP " + r.Kind.String() + " IF EXISTS " + r.Name.Quoted(),
+					kind:   StmtKindDropRoutine,
+					table:  r.ID(),
+					before: r,
+				})
+			}
+		}
+
+		r := *routine
+		r.IfNotExists = false
+		buf.Reset()
+		if err := format.SQL(&buf, &r); err != nil {
+			return fmt.Errorf("failed to format a statement: %w", err)
+		}
+		ctx.append(Stmt{
+			sql:   buf.String(),
+			kind:  StmtKindCreateRoutine,
+			table: routine.ID(),
+			after: routine,
+		})
+	}
+	return nil
+}
+
+// equalRoutine returns whether routine a and b have same definition.
+func equalRoutine(a, b *model.Routine) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	if !equalDefiner(a.Definer, b.Definer) {
+		return false
+	}
+	if a.Comment.Value != b.Comment.Value || a.Deterministic != b.Deterministic {
+		return false
+	}
+	if !strings.EqualFold(viewOrDefault(a.DataAccess, "CONTAINS SQL"), viewOrDefault(b.DataAccess, "CONTAINS SQL")) {
+		return false
+	}
+	if !strings.EqualFold(viewOrDefault(a.SQLSecurity, "DEFINER"), viewOrDefault(b.SQLSecurity, "DEFINER")) {
+		return false
+	}
+	if util.NormalizeSQL(a.Params) != util.NormalizeSQL(b.Params) {
+		return false
+	}
+	if util.NormalizeSQL(a.Returns) != util.NormalizeSQL(b.Returns) {
+		return false
+	}
+	return util.NormalizeSQL(a.Body) == util.NormalizeSQL(b.Body)
+}
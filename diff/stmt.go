@@ -1,15 +1,182 @@
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=Impact -linecomment -output=impact_string_gen.go
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=StmtKind -linecomment -output=stmt_kind_string_gen.go
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=ClauseKind -linecomment -output=clause_kind_string_gen.go
+
 package diff
 
 import (
 	"fmt"
 	"io"
+
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// Impact describes how a statement affects the running database.
+type Impact int
+
+// List of possible Impact values.
+// They are ordered by severity, so they can be compared with each other.
+const (
+	// ImpactSafe means that the statement neither loses data nor blocks writes for a long time.
+	ImpactSafe Impact = iota // safe
+
+	// ImpactLockHeavy means that the statement may rebuild the table,
+	// and block writes to the table during the rebuild.
+	ImpactLockHeavy // lock-heavy
+
+	// ImpactDataLoss means that the statement may lose data.
+	// e.g. DROP TABLE, DROP COLUMN and narrowing the type of a column.
+	ImpactDataLoss // data-loss
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (i Impact) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// StmtKind describes the kind of operation of a statement.
+type StmtKind int
+
+// List of possible StmtKind values.
+const (
+	// StmtKindOther is a statement that doesn't change the schema, such as BEGIN and COMMIT.
+	StmtKindOther StmtKind = iota // other
+
+	StmtKindCreateTable   // create-table
+	StmtKindDropTable     // drop-table
+	StmtKindRenameTable   // rename-table
+	StmtKindAlterTable    // alter-table
+	StmtKindCreateView    // create-view
+	StmtKindDropView      // drop-view
+	StmtKindCreateRoutine // create-routine
+	StmtKindDropRoutine   // drop-routine
+	StmtKindCreateTrigger // create-trigger
+	StmtKindDropTrigger   // drop-trigger
+	StmtKindCreateEvent   // create-event
+	StmtKindAlterEvent    // alter-event
+	StmtKindDropEvent     // drop-event
+	StmtKindAlterDatabase // alter-database
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (i StmtKind) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// ClauseKind describes the kind of an alter specification in ALTER TABLE statements.
+type ClauseKind int
+
+// List of possible ClauseKind values.
+const (
+	ClauseKindAddColumn           ClauseKind = iota // add-column
+	ClauseKindDropColumn                            // drop-column
+	ClauseKindChangeColumn                          // change-column
+	ClauseKindAddIndex                              // add-index
+	ClauseKindDropIndex                             // drop-index
+	ClauseKindTableOption                           // table-option
+	ClauseKindAddCheck                              // add-check
+	ClauseKindDropCheck                             // drop-check
+	ClauseKindAlterCheck                            // alter-check
+	ClauseKindRenameColumn                          // rename-column
+	ClauseKindRenameIndex                           // rename-index
+	ClauseKindPartitionBy                           // partition-by
+	ClauseKindRemovePartitioning                    // remove-partitioning
+	ClauseKindAddPartition                          // add-partition
+	ClauseKindDropPartition                         // drop-partition
+	ClauseKindReorganizePartition                   // reorganize-partition
+	ClauseKindCoalescePartition                     // coalesce-partition
+	ClauseKindAlterColumn                           // alter-column
+	ClauseKindAlterIndex                            // alter-index
 )
 
+// MarshalText implements encoding.TextMarshaler.
+func (i ClauseKind) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
 // Stmt is an SQL statement.
-type Stmt string
+type Stmt struct {
+	sql     string
+	impact  Impact
+	kind    StmtKind
+	table   string
+	before  model.Stmt
+	after   model.Stmt
+	clauses []Clause
+}
 
 func (s Stmt) String() string {
-	return string(s)
+	return s.sql
+}
+
+// Impact returns how the statement affects the running database.
+func (s Stmt) Impact() Impact {
+	return s.impact
+}
+
+// Kind returns the kind of operation of the statement.
+func (s Stmt) Kind() StmtKind {
+	return s.kind
+}
+
+// Table returns the identifier of the table, the view, the routine, the trigger, the event or the database that the statement touches.
+// For RENAME TABLE, it is the identifier of the old table.
+// It returns an empty string if the statement doesn't touch any table.
+func (s Stmt) Table() string {
+	return s.table
+}
+
+// Before returns the model before the statement is executed.
+// It is nil if the statement creates a new object.
+// For CREATE OR REPLACE VIEW, it is the old view if the view is replaced.
+func (s Stmt) Before() model.Stmt {
+	return s.before
+}
+
+// After returns the model after the statement is executed.
+// It is nil if the statement drops the object.
+func (s Stmt) After() model.Stmt {
+	return s.after
+}
+
+// Clauses returns the alter specifications of ALTER TABLE statements.
+func (s Stmt) Clauses() []Clause {
+	return s.clauses
+}
+
+// Clause is an alter specification in ALTER TABLE statements.
+type Clause struct {
+	sql    string
+	impact Impact
+	kind   ClauseKind
+	before model.Stmt
+	after  model.Stmt
+}
+
+func (c Clause) String() string {
+	return c.sql
+}
+
+// Impact returns how the clause affects the running database.
+func (c Clause) Impact() Impact {
+	return c.impact
+}
+
+// Kind returns the kind of the clause.
+func (c Clause) Kind() ClauseKind {
+	return c.kind
+}
+
+// Before returns the column, the index, the CHECK constraint, the table option or the partitioning before the clause is executed.
+// It is nil if the clause adds a new one.
+func (c Clause) Before() model.Stmt {
+	return c.before
+}
+
+// After returns the column, the index, the CHECK constraint, the table option or the partitioning after the clause is executed.
+// It is nil if the clause drops it.
+func (c Clause) After() model.Stmt {
+	return c.after
 }
 
 // Stmts is a list of diff.Stmt.
@@ -21,6 +188,28 @@ func (stmts *Stmts) Append(s Stmt) Stmts {
 	return *stmts
 }
 
+// Impact returns the most severe impact in the statements.
+func (stmts Stmts) Impact() Impact {
+	impact := ImpactSafe
+	for _, s := range stmts {
+		if s.impact > impact {
+			impact = s.impact
+		}
+	}
+	return impact
+}
+
+// Filter returns the statements that have the impact equal to or more severe than impact.
+func (stmts Stmts) Filter(impact Impact) Stmts {
+	var ret Stmts
+	for _, s := range stmts {
+		if s.impact >= impact {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
 // WriteTo writes the statements to dst.
 func (stmts Stmts) WriteTo(dst io.Writer) (int64, error) {
 	eol := []byte(";\n")
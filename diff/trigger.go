@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/format"
+	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// dropTriggers drops the triggers that are removed or changed.
+// Triggers run on changing rows of their tables, so they are dropped before changing tables.
+func (ctx *diffCtx) dropTriggers() error {
+	for _, stmt := range ctx.from {
+		trigger, ok := stmt.(*model.Trigger)
+		if !ok {
+			continue
+		}
+
+		sql := "DROP TRIGGER " + trigger.Name.Quoted()
+		if stmt, ok := ctx.to.Lookup(trigger.ID()); ok {
+			if t, ok := stmt.(*model.Trigger); ok && equalTrigger(trigger, t) {
+				continue
+			}
+			// MySQL can't change the triggers, so the changed ones are dropped and created again.
+			sql = "DROP TRIGGER IF EXISTS " + trigger.Name.Quoted()
+		}
+		ctx.append(Stmt{
+			sql:    sql,
+			kind:   StmtKindDropTrigger,
+			table:  trigger.ID(),
+			before: trigger,
+		})
+	}
+	return nil
+}
+
+// createTriggers creates the triggers that are added or changed.
+// Triggers depend on tables, so they are created after changing tables.
+// They may follow or precede other triggers, so they are created in the order of the new schema.
+func (ctx *diffCtx) createTriggers() error {
+	var buf bytes.Buffer
+	for _, stmt := range ctx.to {
+		trigger, ok := stmt.(*model.Trigger)
+		if !ok {
+			continue
+		}
+
+		if stmt, ok := ctx.from.Lookup(trigger.ID()); ok {
+			if t, ok := stmt.(*model.Trigger); ok && equalTrigger(t, trigger) {
+				continue
+			}
+		}
+
+		t := *trigger
+		t.IfNotExists = false
+		buf.Reset()
+		if err := format.SQL(&buf, &t); err != nil {
+			return fmt.Errorf("failed to format a statement: %w", err)
+		}
+		ctx.append(Stmt{
+			sql:   buf.String(),
+			kind:  StmtKindCreateTrigger,
+			table: trigger.ID(),
+			after: trigger,
+		})
+	}
+	return nil
+}
+
+// equalTrigger returns whether trigger a and b have same definition.
+// The order of the triggers isn't compared, because SHOW CREATE TRIGGER doesn't show it.
+func equalTrigger(a, b *model.Trigger) bool {
+	if !strings.EqualFold(a.Timing, b.Timing) || !strings.EqualFold(a.Event, b.Event) {
+		return false
+	}
+	if !strings.EqualFold(string(a.Table), string(b.Table)) {
+		return false
+	}
+	if !equalDefiner(a.Definer, b.Definer) {
+		return false
+	}
+	return util.NormalizeSQL(a.Body) == util.NormalizeSQL(b.Body)
+}
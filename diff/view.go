@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/format"
+	"github.com/shogo82148/schemalex-deploy/internal/util"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+// dropViews drops the views that are removed.
+// Views don't have any data, so they are dropped before changing tables.
+func (ctx *diffCtx) dropViews() error {
+	ids := ctx.fromSet.Difference(ctx.toSet)
+	for _, id := range ids.ToSlice() {
+		stmt, ok := ctx.from.Lookup(id)
+		if !ok {
+			return fmt.Errorf("failed to lookup view: %q", id)
+		}
+		view, ok := stmt.(*model.View)
+		if !ok {
+			continue
+		}
+		ctx.append(Stmt{
+			sql:    "DROP VIEW " + view.Name.Quoted(),
+			kind:   StmtKindDropView,
+			table:  id,
+			before: view,
+		})
+	}
+	return nil
+}
+
+// createViews creates or replaces the views that are added or changed.
+// Views depend on tables, so they are created after changing tables.
+// They may depend on other views, so they are created in the order of the new schema.
+func (ctx *diffCtx) createViews() error {
+	var buf bytes.Buffer
+	for _, stmt := range ctx.to {
+		view, ok := stmt.(*model.View)
+		if !ok {
+			continue
+		}
+
+		var before *model.View
+		if stmt, ok := ctx.from.Lookup(view.ID()); ok {
+			if v, ok := stmt.(*model.View); ok {
+				if equalView(v, view) {
+					continue
+				}
+				before = v
+			}
+		}
+
+		v := *view
+		v.OrReplace = true
+		buf.Reset()
+		if err := format.SQL(&buf, &v); err != nil {
+			return fmt.Errorf("failed to format a statement: %w", err)
+		}
+		s := Stmt{
+			sql:   buf.String(),
+			kind:  StmtKindCreateView,
+			table: view.ID(),
+			after: view,
+		}
+		if before != nil {
+			s.before = before
+		}
+		ctx.append(s)
+	}
+	return nil
+}
+
+// equalView returns whether view a and b have same definition.
+func equalView(a, b *model.View) bool {
+	if !strings.EqualFold(viewOrDefault(a.Algorithm, "UNDEFINED"), viewOrDefault(b.Algorithm, "UNDEFINED")) {
+		return false
+	}
+	if !strings.EqualFold(viewOrDefault(a.SQLSecurity, "DEFINER"), viewOrDefault(b.SQLSecurity, "DEFINER")) {
+		return false
+	}
+	if !equalDefiner(a.Definer, b.Definer) {
+		return false
+	}
+	if !strings.EqualFold(a.CheckOption, b.CheckOption) {
+		return false
+	}
+	if len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i := range a.Columns {
+		if !strings.EqualFold(string(a.Columns[i]), string(b.Columns[i])) {
+			return false
+		}
+	}
+	return util.NormalizeSQL(a.Definition) == util.NormalizeSQL(b.Definition)
+}
+
+// equalDefiner returns whether definer a and b are same.
+// The default definer is the user who deploys the schema.
+// We don't know who it is, so they are compared only if both are specified.
+func equalDefiner(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+func viewOrDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -88,6 +88,7 @@ func newParseError(ctx *parseCtx, t *Token, msg string, args ...interface{}) err
 	// We're going to append a marker here
 
 	return &parseError{
+		file:    ctx.file,
 		context: fmt.Sprintf(`"%s" <---- AROUND HERE`, ctx.input[ctxbegin:t.Pos]),
 		line:    t.Line,
 		col:     t.Col,
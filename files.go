@@ -0,0 +1,176 @@
+package schemalex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExpandFiles expands the names into the list of SQL files.
+// A directory is expanded into the *.sql files in it recursively, in lexical order.
+// A glob pattern is expanded by filepath.Glob, and it is an error if nothing matches.
+// The duplicated files are removed.
+func ExpandFiles(names ...string) ([]string, error) {
+	var files []string
+	seen := make(map[string]struct{})
+	add := func(file string) {
+		if _, ok := seen[file]; ok {
+			return
+		}
+		seen[file] = struct{}{}
+		files = append(files, file)
+	}
+
+	for _, name := range names {
+		if hasMeta(name) {
+			matches, err := filepath.Glob(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", name, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", name)
+			}
+			for _, m := range matches {
+				if err := expandFile(m, add); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := expandFile(name, add); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func expandFile(name string, add func(string)) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		add(name)
+		return nil
+	}
+	return filepath.WalkDir(name, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".sql") {
+			return nil
+		}
+		add(path)
+		return nil
+	})
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// ReadFiles reads the files expanded by ExpandFiles, and concatenates them.
+// A semicolon is inserted between the files if needed, so that
+// the last statement of a file doesn't continue to the next file.
+// Each file starts with the `schemalex:file` annotation, which resets the current database
+// selected by USE and the delimiter changed by DELIMITER, so that each file is parsed independently.
+func ReadFiles(names ...string) ([]byte, error) {
+	fset, err := ReadFileSet(names...)
+	if err != nil {
+		return nil, err
+	}
+	return fset.Source, nil
+}
+
+// FileSet is the concatenation of the SQL files read by ReadFileSet.
+// It maps the positions in the concatenation back to the files.
+type FileSet struct {
+	// Source is the concatenated content of the files.
+	Source []byte
+
+	files []fileStart
+}
+
+// fileStart is the line where the file starts in FileSet.Source.
+type fileStart struct {
+	name  string
+	line  int
+	lines int // the number of the lines in the file
+}
+
+// ReadFileSet is like ReadFiles, but it also records where each file starts in the concatenation.
+func ReadFileSet(names ...string) (*FileSet, error) {
+	files, err := ExpandFiles(names...)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 1 {
+		src, err := os.ReadFile(files[0])
+		if err != nil {
+			return nil, err
+		}
+		return &FileSet{
+			Source: src,
+			files:  []fileStart{{name: files[0], line: 1, lines: countLines(src)}},
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	fset := &FileSet{}
+	line := 1
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "-- %s %s\n", annotationFile, file)
+		line++
+		fset.files = append(fset.files, fileStart{name: file, line: line, lines: countLines(src)})
+		buf.Write(src)
+		if len(src) > 0 && src[len(src)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		if trimmed := bytes.TrimSpace(src); len(trimmed) > 0 && trimmed[len(trimmed)-1] != ';' {
+			buf.WriteString(";\n")
+		}
+		line = bytes.Count(buf.Bytes(), []byte("\n")) + 1
+	}
+	fset.Source = buf.Bytes()
+	return fset, nil
+}
+
+// Locate converts the ParseError in Source into the one that reports the file name and the line in the file.
+// The other errors are returned as they are.
+func (fset *FileSet) Locate(err error) error {
+	var pe *parseError
+	if fset == nil || !errors.As(err, &pe) || pe.file != "" {
+		return err
+	}
+	i := sort.Search(len(fset.files), func(i int) bool {
+		return fset.files[i].line > pe.line
+	}) - 1
+	if i < 0 {
+		return err
+	}
+	located := *pe
+	located.file = fset.files[i].name
+	located.line = pe.line - fset.files[i].line + 1
+	if located.line > fset.files[i].lines {
+		// the error is in the semicolon inserted after the file.
+		located.line = fset.files[i].lines
+	}
+	return &located
+}
+
+func countLines(src []byte) int {
+	n := bytes.Count(src, []byte("\n"))
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,172 @@
+package schemalex_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/model"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"tables/b.sql":     "",
+		"tables/a.sql":     "",
+		"tables/sub/c.sql": "",
+		"tables/README.md": "",
+		"views/v1.sql":     "",
+		"views/v2.sql":     "",
+	})
+
+	files, err := schemalex.ExpandFiles(
+		filepath.Join(dir, "tables"),
+		filepath.Join(dir, "views", "*.sql"),
+		filepath.Join(dir, "tables", "a.sql"), // duplicated
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "tables", "a.sql"),
+		filepath.Join(dir, "tables", "b.sql"),
+		filepath.Join(dir, "tables", "sub", "c.sql"),
+		filepath.Join(dir, "views", "v1.sql"),
+		filepath.Join(dir, "views", "v2.sql"),
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Errorf("files mismatch (-want/+got):\n%s", diff)
+	}
+
+	if _, err := schemalex.ExpandFiles(filepath.Join(dir, "*.txt")); err == nil {
+		t.Error("want error, but not")
+	}
+	if _, err := schemalex.ExpandFiles(filepath.Join(dir, "missing.sql")); err == nil {
+		t.Error("want error, but not")
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.sql": "CREATE TABLE a (id INT PRIMARY KEY)",
+		"b.sql": "CREATE TABLE b (id INT PRIMARY KEY);\n",
+	})
+
+	src, err := schemalex.ReadFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "-- schemalex:file " + filepath.Join(dir, "a.sql") + "\n" +
+		"CREATE TABLE a (id INT PRIMARY KEY)\n;\n" +
+		"-- schemalex:file " + filepath.Join(dir, "b.sql") + "\n" +
+		"CREATE TABLE b (id INT PRIMARY KEY);\n"
+	if diff := cmp.Diff(want, string(src)); diff != "" {
+		t.Errorf("source mismatch (-want/+got):\n%s", diff)
+	}
+
+	stmts, err := schemalex.New().Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Errorf("want 2 statements, got %d", len(stmts))
+	}
+}
+
+func TestReadFiles_Independent(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.sql": "CREATE TABLE a (id INT PRIMARY KEY);\nUSE other;\nDELIMITER //\n",
+		"b.sql": "CREATE TABLE b (id INT PRIMARY KEY);\n",
+	})
+
+	src, err := schemalex.ReadFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := schemalex.New().Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("want 2 statements, got %d", len(stmts))
+	}
+	table, ok := stmts[1].(*model.Table)
+	if !ok {
+		t.Fatalf("want *model.Table, got %T", stmts[1])
+	}
+	if want, got := "`b`", table.QuotedName(); want != got {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.sql": "CREATE TABLE a (id INT PRIMARY KEY);\n",
+		"b.sql": "CREATE TABLE b (id INT PRIMARY KEY);\nCREATE TABLE c",
+	})
+
+	_, err := schemalex.New().ParseFiles(dir)
+	if err == nil {
+		t.Fatal("parse should fail")
+	}
+
+	var pe schemalex.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("want ParseError, got %T", err)
+	}
+	if want, got := filepath.Join(dir, "b.sql"), pe.File(); want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := 2, pe.Line(); want != got {
+		t.Errorf("want %d, got %d", want, got)
+	}
+}
+
+func TestFileSet_Locate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.sql": "CREATE TABLE a (id INT PRIMARY KEY)",
+		"b.sql": "CREATE TABLE b (id INT PRIMARY KEY);\nCREATE TABLE c",
+	})
+
+	fset, err := schemalex.ReadFileSet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = schemalex.New().Parse(fset.Source)
+	if err == nil {
+		t.Fatal("parse should fail")
+	}
+
+	var pe schemalex.ParseError
+	if !errors.As(fset.Locate(err), &pe) {
+		t.Fatalf("want ParseError, got %T", err)
+	}
+	if want, got := filepath.Join(dir, "b.sql"), pe.File(); want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := 2, pe.Line(); want != got {
+		t.Errorf("want %d, got %d", want, got)
+	}
+
+	other := errors.New("other error")
+	if got := fset.Locate(other); got != other {
+		t.Errorf("want %v, got %v", other, got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/shogo82148/schemalex-deploy/internal/util"
 	"github.com/shogo82148/schemalex-deploy/model"
@@ -14,6 +15,7 @@ type fmtCtx struct {
 	curIndent string
 	dst       io.Writer
 	indent    string
+	version   *model.ServerVersion
 }
 
 func newFmtCtx(dst io.Writer) *fmtCtx {
@@ -27,6 +29,7 @@ func (ctx *fmtCtx) clone() *fmtCtx {
 		curIndent: ctx.curIndent,
 		dst:       ctx.dst,
 		indent:    ctx.indent,
+		version:   ctx.version,
 	}
 }
 
@@ -40,6 +43,7 @@ func SQL(dst io.Writer, v interface{}, options ...Option) error {
 
 	ctx := newFmtCtx(dst)
 	ctx.indent = opts.indent
+	ctx.version = opts.version
 	return format(ctx, v)
 }
 
@@ -69,6 +73,20 @@ func format(ctx *fmtCtx, v interface{}) error {
 		return formatIndex(ctx, v)
 	case *model.Reference:
 		return formatReference(ctx, v)
+	case *model.Check:
+		return formatCheck(ctx, v)
+	case *model.View:
+		return formatView(ctx, v)
+	case *model.Routine:
+		return formatRoutine(ctx, v)
+	case *model.Trigger:
+		return formatTrigger(ctx, v)
+	case *model.Event:
+		return formatEvent(ctx, v)
+	case *model.Partition:
+		return formatPartition(ctx, v)
+	case *model.PartitionDefinition:
+		return formatPartitionDefinition(ctx, v)
 	default:
 		return fmt.Errorf("unsupported model type: %T", v)
 	}
@@ -82,7 +100,188 @@ func formatDatabase(ctx *fmtCtx, d *model.Database) error {
 	}
 	buf.WriteByte(' ')
 	buf.WriteString(d.Name.Quoted())
-	buf.WriteByte(';')
+	if d.CharacterSet.Valid {
+		buf.WriteString(" DEFAULT CHARACTER SET = ")
+		buf.WriteString(string(d.CharacterSet.Ident))
+	}
+	if d.Collation.Valid {
+		buf.WriteString(" DEFAULT COLLATE = ")
+		buf.WriteString(string(d.Collation.Ident))
+	}
+	if d.Encryption.Valid {
+		buf.WriteString(" DEFAULT ENCRYPTION = '")
+		buf.WriteString(d.Encryption.Value)
+		buf.WriteByte('\'')
+	}
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func formatView(ctx *fmtCtx, view *model.View) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("CREATE")
+	if view.OrReplace {
+		buf.WriteString(" OR REPLACE")
+	}
+	if view.Algorithm != "" {
+		buf.WriteString(" ALGORITHM = ")
+		buf.WriteString(view.Algorithm)
+	}
+	if view.Definer != "" {
+		buf.WriteString(" DEFINER = ")
+		buf.WriteString(view.Definer)
+	}
+	if view.SQLSecurity != "" {
+		buf.WriteString(" SQL SECURITY ")
+		buf.WriteString(view.SQLSecurity)
+	}
+	buf.WriteString(" VIEW ")
+	buf.WriteString(view.Name.Quoted())
+	if len(view.Columns) > 0 {
+		buf.WriteString(" (")
+		for i, col := range view.Columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(col.Quoted())
+		}
+		buf.WriteByte(')')
+	}
+	buf.WriteString(" AS ")
+	buf.WriteString(view.Definition)
+	if view.CheckOption != "" {
+		buf.WriteString(" WITH ")
+		buf.WriteString(view.CheckOption)
+		buf.WriteString(" CHECK OPTION")
+	}
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func formatRoutine(ctx *fmtCtx, routine *model.Routine) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("CREATE")
+	if routine.Definer != "" {
+		buf.WriteString(" DEFINER = ")
+		buf.WriteString(routine.Definer)
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(routine.Kind.String())
+	if routine.IfNotExists {
+		buf.WriteString(" IF NOT EXISTS")
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(routine.Name.Quoted())
+	buf.WriteString(" (")
+	buf.WriteString(routine.Params)
+	buf.WriteByte(')')
+	if routine.Kind == model.RoutineKindFunction {
+		buf.WriteString(" RETURNS ")
+		buf.WriteString(routine.Returns)
+	}
+	if routine.Comment.Valid {
+		buf.WriteString(" COMMENT '")
+		buf.WriteString(strings.ReplaceAll(routine.Comment.Value, "'", "''"))
+		buf.WriteByte('\'')
+	}
+	if routine.Deterministic {
+		buf.WriteString(" DETERMINISTIC")
+	}
+	if routine.DataAccess != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(routine.DataAccess)
+	}
+	if routine.SQLSecurity != "" {
+		buf.WriteString(" SQL SECURITY ")
+		buf.WriteString(routine.SQLSecurity)
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(routine.Body)
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func formatTrigger(ctx *fmtCtx, trigger *model.Trigger) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("CREATE")
+	if trigger.Definer != "" {
+		buf.WriteString(" DEFINER = ")
+		buf.WriteString(trigger.Definer)
+	}
+	buf.WriteString(" TRIGGER")
+	if trigger.IfNotExists {
+		buf.WriteString(" IF NOT EXISTS")
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(trigger.Name.Quoted())
+	buf.WriteByte(' ')
+	buf.WriteString(trigger.Timing)
+	buf.WriteByte(' ')
+	buf.WriteString(trigger.Event)
+	buf.WriteString(" ON ")
+	buf.WriteString(trigger.Table.Quoted())
+	buf.WriteString(" FOR EACH ROW")
+	if trigger.Order != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(trigger.Order)
+		buf.WriteByte(' ')
+		buf.WriteString(trigger.OrderTrigger.Quoted())
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(trigger.Body)
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func formatEvent(ctx *fmtCtx, event *model.Event) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("CREATE")
+	if event.Definer != "" {
+		buf.WriteString(" DEFINER = ")
+		buf.WriteString(event.Definer)
+	}
+	buf.WriteString(" EVENT")
+	if event.IfNotExists {
+		buf.WriteString(" IF NOT EXISTS")
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(event.Name.Quoted())
+	buf.WriteString(" ON SCHEDULE ")
+	buf.WriteString(event.Schedule)
+	if event.Preserve {
+		buf.WriteString(" ON COMPLETION PRESERVE")
+	}
+	switch {
+	case event.Status == "DISABLE ON REPLICA" && (ctx.version == nil || !ctx.version.SupportsReplicaKeyword()):
+		// the old servers only accept SLAVE.
+		buf.WriteString(" DISABLE ON SLAVE")
+	case event.Status != "":
+		buf.WriteByte(' ')
+		buf.WriteString(event.Status)
+	}
+	if event.Comment.Valid {
+		buf.WriteString(" COMMENT '")
+		buf.WriteString(strings.ReplaceAll(event.Comment.Value, "'", "''"))
+		buf.WriteByte('\'')
+	}
+	buf.WriteString("\nDO ")
+	buf.WriteString(event.Body)
 
 	if _, err := buf.WriteTo(ctx.dst); err != nil {
 		return err
@@ -93,7 +292,12 @@ func formatDatabase(ctx *fmtCtx, d *model.Database) error {
 func formatTableOption(ctx *fmtCtx, option *model.TableOption) error {
 	var buf bytes.Buffer
 	buf.WriteString(option.Key)
-	buf.WriteString(" = ")
+	if option.Key == "STORAGE" {
+		// STORAGE {DISK | MEMORY} doesn't accept the equal sign.
+		buf.WriteByte(' ')
+	} else {
+		buf.WriteString(" = ")
+	}
 	if option.NeedQuotes {
 		buf.WriteByte('\'')
 		buf.WriteString(option.Value)
@@ -122,7 +326,7 @@ func formatTable(ctx *fmtCtx, table *model.Table) error {
 	}
 
 	buf.WriteByte(' ')
-	buf.WriteString(table.Name.Quoted())
+	buf.WriteString(table.QuotedName())
 
 	if table.LikeTable.Valid {
 		buf.WriteString(" LIKE ")
@@ -140,7 +344,7 @@ func formatTable(ctx *fmtCtx, table *model.Table) error {
 			if err := formatTableColumn(newctx, col); err != nil {
 				return err
 			}
-			if i < len(table.Columns)-1 || len(table.Indexes) > 0 {
+			if i < len(table.Columns)-1 || len(table.Indexes) > 0 || len(table.Checks) > 0 {
 				buf.WriteByte(',')
 			}
 			i++
@@ -151,12 +355,22 @@ func formatTable(ctx *fmtCtx, table *model.Table) error {
 			if err := formatIndex(newctx, idx); err != nil {
 				return err
 			}
-			if i < len(table.Indexes)-1 {
+			if i < len(table.Indexes)-1 || len(table.Checks) > 0 {
 				buf.WriteByte(',')
 			}
 			i++
 		}
 
+		for i, check := range table.Checks {
+			buf.WriteByte('\n')
+			if err := formatCheck(newctx, check); err != nil {
+				return err
+			}
+			if i < len(table.Checks)-1 {
+				buf.WriteByte(',')
+			}
+		}
+
 		buf.WriteString("\n)")
 
 		if l := len(table.Options); l > 0 {
@@ -172,6 +386,15 @@ func formatTable(ctx *fmtCtx, table *model.Table) error {
 				i++
 			}
 		}
+
+		if table.Partition != nil {
+			buf.WriteByte('\n')
+			partctx := ctx.clone()
+			partctx.dst = &buf
+			if err := formatPartition(partctx, table.Partition); err != nil {
+				return err
+			}
+		}
 	}
 
 	if _, err := buf.WriteTo(ctx.dst); err != nil {
@@ -228,7 +451,7 @@ func formatTableColumn(ctx *fmtCtx, col *model.TableColumn) error {
 		buf.Truncate(buf.Len() - 1)
 		buf.WriteByte(')')
 	default:
-		if col.Length != nil {
+		if col.Length != nil && (ctx.version == nil || !ctx.version.IgnoresDisplayWidth(col)) {
 			l := col.Length
 			buf.WriteString(" (")
 			buf.WriteString(l.Length)
@@ -262,6 +485,18 @@ func formatTableColumn(ctx *fmtCtx, col *model.TableColumn) error {
 		buf.WriteString(col.Collation.Quoted())
 	}
 
+	if col.Generated.Valid {
+		buf.WriteString(" GENERATED ALWAYS AS (")
+		buf.WriteString(col.Generated.Value)
+		buf.WriteByte(')')
+		switch col.GeneratedStorage {
+		case model.GeneratedStorageVirtual:
+			buf.WriteString(" VIRTUAL")
+		case model.GeneratedStorageStored:
+			buf.WriteString(" STORED")
+		}
+	}
+
 	if col.AutoUpdate.Valid {
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(col.AutoUpdate.Value)
@@ -284,7 +519,11 @@ func formatTableColumn(ctx *fmtCtx, col *model.TableColumn) error {
 
 	if col.Default.Valid {
 		buf.WriteString(" DEFAULT ")
-		if col.Default.Quoted {
+		if col.Default.Expr {
+			buf.WriteByte('(')
+			buf.WriteString(col.Default.Value)
+			buf.WriteByte(')')
+		} else if col.Default.Quoted {
 			buf.WriteByte('\'')
 			buf.WriteString(col.Default.Value)
 			buf.WriteByte('\'')
@@ -293,6 +532,10 @@ func formatTableColumn(ctx *fmtCtx, col *model.TableColumn) error {
 		}
 	}
 
+	if col.Invisible {
+		buf.WriteString(" INVISIBLE")
+	}
+
 	if col.AutoIncrement {
 		buf.WriteString(" AUTO_INCREMENT")
 	}
@@ -362,7 +605,13 @@ func formatIndex(ctx *fmtCtx, index *model.Index) error {
 	}
 
 	for i, col := range index.Columns {
-		buf.WriteString(col.Name.Quoted())
+		if col.Expr.Valid {
+			buf.WriteByte('(')
+			buf.WriteString(col.Expr.Value)
+			buf.WriteByte(')')
+		} else {
+			buf.WriteString(col.Name.Quoted())
+		}
 		if col.Length.Valid {
 			buf.WriteByte('(')
 			buf.WriteString(col.Length.Value)
@@ -400,6 +649,10 @@ func formatIndex(ctx *fmtCtx, index *model.Index) error {
 		}
 	}
 
+	if index.Invisible {
+		buf.WriteString(" INVISIBLE")
+	}
+
 	if ref := index.Reference; ref != nil {
 		newctx := ctx.clone()
 		newctx.dst = &buf
@@ -416,12 +669,34 @@ func formatIndex(ctx *fmtCtx, index *model.Index) error {
 	return nil
 }
 
+func formatCheck(ctx *fmtCtx, check *model.Check) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(ctx.curIndent)
+	if check.Name.Valid {
+		buf.WriteString("CONSTRAINT ")
+		buf.WriteString(check.Name.Quoted())
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("CHECK (")
+	buf.WriteString(check.Expr)
+	buf.WriteByte(')')
+	if check.NotEnforced {
+		buf.WriteString(" NOT ENFORCED")
+	}
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
+
 func formatReference(ctx *fmtCtx, r *model.Reference) error {
 	var buf bytes.Buffer
 
 	buf.WriteString(ctx.curIndent)
 	buf.WriteString("REFERENCES ")
-	buf.WriteString(r.TableName.Quoted())
+	buf.WriteString(r.QuotedTableName())
 	buf.WriteString(" (")
 
 	ch := r.Columns
@@ -477,3 +752,121 @@ func writeReferenceOption(buf *bytes.Buffer, prefix string, opt model.ReferenceO
 		panic(fmt.Errorf("unknown reference option: %d", int(opt)))
 	}
 }
+
+// formatPartition formats the partition options, such as `PARTITION BY RANGE (expr) (...)`.
+func formatPartition(ctx *fmtCtx, part *model.Partition) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(ctx.curIndent)
+	buf.WriteString("PARTITION BY ")
+	writePartitionType(&buf, part)
+	if part.Num.Valid {
+		buf.WriteString(" PARTITIONS ")
+		buf.WriteString(part.Num.Value)
+	}
+
+	if sub := part.SubPartition; sub != nil {
+		buf.WriteString(" SUBPARTITION BY ")
+		writePartitionType(&buf, sub)
+		if sub.Num.Valid {
+			buf.WriteString(" SUBPARTITIONS ")
+			buf.WriteString(sub.Num.Value)
+		}
+	}
+
+	if len(part.Definitions) > 0 {
+		newctx := ctx.clone()
+		newctx.curIndent = newctx.indent + newctx.curIndent
+		newctx.dst = &buf
+
+		buf.WriteString(" (")
+		for i, def := range part.Definitions {
+			buf.WriteByte('\n')
+			if err := formatPartitionDefinition(newctx, def); err != nil {
+				return err
+			}
+			if i < len(part.Definitions)-1 {
+				buf.WriteByte(',')
+			}
+		}
+		buf.WriteByte('\n')
+		buf.WriteString(ctx.curIndent)
+		buf.WriteByte(')')
+	}
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writePartitionType writes the type of partitioning, such as `RANGE (expr)` and `LINEAR KEY (columns)`.
+func writePartitionType(buf *bytes.Buffer, part *model.Partition) {
+	if part.Linear {
+		buf.WriteString("LINEAR ")
+	}
+	buf.WriteString(part.Type.String())
+	if part.Algorithm.Valid {
+		buf.WriteString(" ALGORITHM = ")
+		buf.WriteString(part.Algorithm.Value)
+	}
+	buf.WriteString(" (")
+	switch part.Type {
+	case model.PartitionTypeRangeColumns, model.PartitionTypeListColumns, model.PartitionTypeKey:
+		for i, col := range part.Columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(col.Quoted())
+		}
+	default:
+		buf.WriteString(part.Expr)
+	}
+	buf.WriteByte(')')
+}
+
+// formatPartitionDefinition formats a partition or a subpartition.
+func formatPartitionDefinition(ctx *fmtCtx, def *model.PartitionDefinition) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(ctx.curIndent)
+	buf.WriteString("PARTITION ")
+	buf.WriteString(def.Name.Quoted())
+	if def.Values.Valid {
+		buf.WriteString(" VALUES ")
+		buf.WriteString(def.Values.Value)
+	}
+
+	newctx := ctx.clone()
+	newctx.curIndent = ""
+	newctx.dst = &buf
+	for _, opt := range def.Options {
+		buf.WriteByte(' ')
+		if err := formatTableOption(newctx, opt); err != nil {
+			return err
+		}
+	}
+
+	if len(def.SubPartitions) > 0 {
+		buf.WriteString(" (")
+		for i, sub := range def.SubPartitions {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString("SUBPARTITION ")
+			buf.WriteString(sub.Name.Quoted())
+			for _, opt := range sub.Options {
+				buf.WriteByte(' ')
+				if err := formatTableOption(newctx, opt); err != nil {
+					return err
+				}
+			}
+		}
+		buf.WriteByte(')')
+	}
+
+	if _, err := buf.WriteTo(ctx.dst); err != nil {
+		return err
+	}
+	return nil
+}
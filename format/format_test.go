@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/shogo82148/schemalex-deploy"
+	"github.com/shogo82148/schemalex-deploy/model"
 )
 
 type Spec struct {
@@ -51,19 +52,45 @@ func TestFormat(t *testing.T) {
 		})
 	}
 
-	// create database are ignored
 	parse("CreateDatabase", &Spec{
-		Input: "create DATABASE hoge",
+		Input:  "create DATABASE hoge",
+		Expect: "CREATE DATABASE `hoge`;\n",
 	})
 	parse("CreateDatabaseIfNotExists", &Spec{
-		Input: "create DATABASE IF NOT EXISTS hoge",
+		Input:  "create DATABASE IF NOT EXISTS hoge",
+		Expect: "CREATE DATABASE IF NOT EXISTS `hoge`;\n",
 	})
 	parse("CreateDatabase17", &Spec{
 		Input: "create DATABASE 17",
 		Error: true,
 	})
 	parse("MultipleCreateDatabase", &Spec{
-		Input: "create DATABASE hoge; create database fuga;",
+		Input:  "create DATABASE hoge; create database fuga;",
+		Expect: "CREATE DATABASE `hoge`;\nCREATE DATABASE `fuga`;\n",
+	})
+	parse("CreateSchema", &Spec{
+		Input:  "create SCHEMA hoge",
+		Expect: "CREATE DATABASE `hoge`;\n",
+	})
+	parse("CreateDatabaseWithOptions", &Spec{
+		Input:  "create database hoge default character set utf8mb4 collate = utf8mb4_bin encryption 'Y'",
+		Expect: "CREATE DATABASE `hoge` DEFAULT CHARACTER SET = utf8mb4 DEFAULT COLLATE = utf8mb4_bin DEFAULT ENCRYPTION = 'Y';\n",
+	})
+	parse("CreateDatabaseWithCharset", &Spec{
+		Input:  "create database hoge charset = latin1",
+		Expect: "CREATE DATABASE `hoge` DEFAULT CHARACTER SET = latin1;\n",
+	})
+	parse("CreateDatabaseVersionComment", &Spec{
+		Input:  "CREATE DATABASE /*!32312 IF NOT EXISTS*/ `hoge` /*!40100 DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci */ /*!80016 DEFAULT ENCRYPTION='N' */;",
+		Expect: "CREATE DATABASE IF NOT EXISTS `hoge` DEFAULT CHARACTER SET = utf8mb4 DEFAULT COLLATE = utf8mb4_0900_ai_ci DEFAULT ENCRYPTION = 'N';\n",
+	})
+	parse("CreateDatabaseUnknownOption", &Spec{
+		Input: "create database hoge unknown_option = 1",
+		Error: true,
+	})
+	parse("CreateDatabaseDefaultOnly", &Spec{
+		Input: "create database hoge default;",
+		Error: true,
 	})
 
 	parse("CreateTableIntegerNoWidth", &Spec{
@@ -223,6 +250,48 @@ primary key (id, c)
 			"FOREIGN KEY `fk_c` (`c`) REFERENCES `fuga` (`id`)\n" +
 			");\n",
 	})
+	parse("WithQualifiedReferenceForeignKey", &Spec{
+		Input: "create table hoge ( `id` bigint unsigned not null auto_increment,\n" +
+			"`c` varchar(20) not null,\n" +
+			"FOREIGN KEY `fk_c` (`c`) REFERENCES other.`fuga` (`id`) )",
+		Expect: "CREATE TABLE `hoge` (\n" +
+			"`id` BIGINT (20) UNSIGNED NOT NULL AUTO_INCREMENT,\n" +
+			"`c` VARCHAR (20) NOT NULL,\n" +
+			"FOREIGN KEY `fk_c` (`c`) REFERENCES `other`.`fuga` (`id`)\n" +
+			");\n",
+	})
+	parse("QualifiedTableName", &Spec{
+		Input: "create table `db`.hoge ( `id` int not null )",
+		Expect: "CREATE TABLE `db`.`hoge` (\n" +
+			"`id` INT (11) NOT NULL\n" +
+			");\n",
+	})
+	parse("QualifiedTableNameWithoutTable", &Spec{
+		Input: "create table db. ( `id` int not null )",
+		Error: true,
+	})
+	parse("UseDatabase", &Spec{
+		Input: "use db1;\n" +
+			"create table hoge ( `id` int not null, FOREIGN KEY (`id`) REFERENCES db1.fuga (`id`) );\n" +
+			"use `db2`;\n" +
+			"create table hoge ( `id` int not null, FOREIGN KEY (`id`) REFERENCES db1.fuga (`id`) );\n" +
+			"create table db1.piyo ( `id` int not null )",
+		Expect: "CREATE TABLE `db1`.`hoge` (\n" +
+			"`id` INT (11) NOT NULL,\n" +
+			"FOREIGN KEY (`id`) REFERENCES `fuga` (`id`)\n" +
+			");\n" +
+			"CREATE TABLE `db2`.`hoge` (\n" +
+			"`id` INT (11) NOT NULL,\n" +
+			"FOREIGN KEY (`id`) REFERENCES `db1`.`fuga` (`id`)\n" +
+			");\n" +
+			"CREATE TABLE `db1`.`piyo` (\n" +
+			"`id` INT (11) NOT NULL\n" +
+			");\n",
+	})
+	parse("UseWithoutDatabase", &Spec{
+		Input: "use;",
+		Error: true,
+	})
 	parse("WithMatchReferenceForeignKey", &Spec{
 		Input: "create table hoge ( `id` bigint unsigned not null auto_increment,\n" +
 			"`c` varchar(20) not null,\n" +
@@ -466,6 +535,15 @@ primary key (id, c)
 /*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;`,
 		Expect: "",
 	})
+	parse("AlterTableInExecutableComments", &Spec{
+		Input: "CREATE TABLE `t` (`id` int NOT NULL);\n" +
+			"/*!40000 ALTER TABLE `t` DISABLE KEYS */;\n" +
+			"/*!40000 ALTER TABLE `t` ENABLE KEYS */;\n" +
+			"/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL\n" +
+			");\n",
+	})
 	parse("CommentsAndStatementsMixedTogether", &Spec{
 		Input: "/* hello, world*/;\n" +
 			"CREATE TABLE foo (\n" +
@@ -501,7 +579,7 @@ primary key (id, c)
 			"`context` JSON DEFAULT NULL,\n" +
 			"`created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,\n" +
 			"PRIMARY KEY (`id`),\n" +
-			"INDEX `created_at` (`created_at` DESC),\n" +
+			"INDEX `created_at` (`created_at` DESC) INVISIBLE,\n" +
 			"INDEX `user_id_idx` (`user_id`),\n" +
 			"INDEX `some_table__user_id` (`user_id`),\n" +
 			"CONSTRAINT `some_table__user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE SET NULL\n" +
@@ -528,7 +606,125 @@ primary key (id, c)
 			"`t_id` CHAR (17) NOT NULL,\n" +
 			"`t_type` SMALLINT (6) NOT NULL,\n" +
 			"`cur_date` DATETIME NOT NULL\n" +
-			") ENGINE = InnoDB, DEFAULT CHARACTER SET = utf8;\n",
+			") ENGINE = InnoDB, DEFAULT CHARACTER SET = utf8\n" +
+			"PARTITION BY LIST (`t_type`) (\n" +
+			"PARTITION `p_1` VALUES IN (1) ENGINE = InnoDB,\n" +
+			"PARTITION `p_100` VALUES IN (100) ENGINE = InnoDB\n" +
+			");\n",
+	})
+	parse("PartitionByRange", &Spec{
+		Input: "CREATE TABLE t (id INT NOT NULL, created DATE NOT NULL)\n" +
+			"PARTITION BY RANGE (YEAR(created)) (\n" +
+			"  PARTITION p0 VALUES LESS THAN (1990) COMMENT 'old',\n" +
+			"  PARTITION p1 VALUES LESS THAN MAXVALUE\n" +
+			")",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL,\n" +
+			"`created` DATE NOT NULL\n" +
+			")\n" +
+			"PARTITION BY RANGE (YEAR(created)) (\n" +
+			"PARTITION `p0` VALUES LESS THAN (1990) COMMENT = 'old',\n" +
+			"PARTITION `p1` VALUES LESS THAN MAXVALUE\n" +
+			");\n",
+	})
+	parse("PartitionByRangeColumnsWithSubpartitions", &Spec{
+		Input: "CREATE TABLE t (a INT, b DATE) ENGINE=InnoDB\n" +
+			"PARTITION BY RANGE COLUMNS (a, `b`) SUBPARTITION BY LINEAR HASH (TO_DAYS(b)) SUBPARTITIONS 2 (\n" +
+			"  PARTITION p0 VALUES LESS THAN (10, '2020-01-01') (SUBPARTITION s0, SUBPARTITION s1 STORAGE ENGINE = InnoDB),\n" +
+			"  PARTITION p1 VALUES LESS THAN (MAXVALUE, MAXVALUE) (SUBPARTITION s2, SUBPARTITION s3)\n" +
+			")",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`a` INT (11) DEFAULT NULL,\n" +
+			"`b` DATE DEFAULT NULL\n" +
+			") ENGINE = InnoDB\n" +
+			"PARTITION BY RANGE COLUMNS (`a`, `b`) SUBPARTITION BY LINEAR HASH (TO_DAYS(b)) SUBPARTITIONS 2 (\n" +
+			"PARTITION `p0` VALUES LESS THAN (10, '2020-01-01') (SUBPARTITION `s0`, SUBPARTITION `s1` ENGINE = InnoDB),\n" +
+			"PARTITION `p1` VALUES LESS THAN (MAXVALUE, MAXVALUE) (SUBPARTITION `s2`, SUBPARTITION `s3`)\n" +
+			");\n",
+	})
+	parse("PartitionByKey", &Spec{
+		Input: "CREATE TABLE t (id INT NOT NULL PRIMARY KEY) PARTITION BY LINEAR KEY ALGORITHM=2 () PARTITIONS 4",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL,\n" +
+			"PRIMARY KEY (`id`)\n" +
+			")\n" +
+			"PARTITION BY LINEAR KEY ALGORITHM = 2 () PARTITIONS 4;\n",
+	})
+	parse("PartitionByList", &Spec{
+		Input: "CREATE TABLE t (id INT NOT NULL) PARTITION BY LIST (id) (PARTITION p0 VALUES IN (1, 2), PARTITION p1 VALUES IN (3))",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL\n" +
+			")\n" +
+			"PARTITION BY LIST (id) (\n" +
+			"PARTITION `p0` VALUES IN (1, 2),\n" +
+			"PARTITION `p1` VALUES IN (3)\n" +
+			");\n",
+	})
+	parse("DefaultExpression", &Spec{
+		Input: "CREATE TABLE t (id BINARY(16) NOT NULL DEFAULT (UUID_TO_BIN(UUID())), tags JSON DEFAULT (JSON_ARRAY()))",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` BINARY (16) NOT NULL DEFAULT (UUID_TO_BIN(UUID())),\n" +
+			"`tags` JSON DEFAULT (JSON_ARRAY())\n" +
+			");\n",
+	})
+	parse("FunctionalIndex", &Spec{
+		Input: "CREATE TABLE t (email VARCHAR(255) NOT NULL, a INT, b INT, INDEX idx_email ((lower(`email`))), UNIQUE KEY (a, (a + b) DESC))",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`email` VARCHAR (255) NOT NULL,\n" +
+			"`a` INT (11) DEFAULT NULL,\n" +
+			"`b` INT (11) DEFAULT NULL,\n" +
+			"INDEX `idx_email` ((lower(`email`))),\n" +
+			"UNIQUE INDEX (`a`, (a + b) DESC)\n" +
+			");\n",
+	})
+	parse("FunctionalIndexWithLength", &Spec{
+		Input: "CREATE TABLE t (email VARCHAR(255) NOT NULL, INDEX ((lower(email))(10)))",
+		Error: true,
+	})
+	parse("InvisibleColumnAndIndex", &Spec{
+		Input: "CREATE TABLE t (id INT NOT NULL, a INT NOT NULL DEFAULT 0 INVISIBLE COMMENT 'hidden', b TEXT, c INT VISIBLE, INDEX idx_a (a) INVISIBLE, FULLTEXT INDEX ft_b (b) INVISIBLE, UNIQUE KEY (c) VISIBLE)",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL,\n" +
+			"`a` INT (11) NOT NULL DEFAULT 0 INVISIBLE COMMENT 'hidden',\n" +
+			"`b` TEXT,\n" +
+			"`c` INT (11) DEFAULT NULL,\n" +
+			"INDEX `idx_a` (`a`) INVISIBLE,\n" +
+			"FULLTEXT INDEX `ft_b` (`b`) INVISIBLE,\n" +
+			"UNIQUE INDEX (`c`)\n" +
+			");\n",
+	})
+	parse("InvisibleColumnInExecutableComment", &Spec{
+		Input: "CREATE TABLE `t` (`id` int NOT NULL, `a` int DEFAULT NULL /*!80023 INVISIBLE */)",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL,\n" +
+			"`a` INT (11) DEFAULT NULL INVISIBLE\n" +
+			");\n",
+	})
+	parse("Tablespace", &Spec{
+		Input: "CREATE TABLE t (id INT NOT NULL) TABLESPACE ts1 STORAGE DISK ENGINE = NDB",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL\n" +
+			") TABLESPACE = `ts1`, STORAGE DISK, ENGINE = NDB;\n",
+	})
+	parse("TablespaceInExecutableComment", &Spec{
+		Input: "CREATE TABLE `t` (`id` int NOT NULL) /*!50100 TABLESPACE `innodb_system` */ ENGINE=InnoDB",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`id` INT (11) NOT NULL\n" +
+			") TABLESPACE = `innodb_system`, ENGINE = InnoDB;\n",
+	})
+	parse("Union", &Spec{
+		Input: "CREATE TABLE total (a INT NOT NULL) ENGINE=MERGE UNION=(t1,`t2`) INSERT_METHOD=LAST",
+		Expect: "CREATE TABLE `total` (\n" +
+			"`a` INT (11) NOT NULL\n" +
+			") ENGINE = MERGE, UNION = (`t1`, `t2`), INSERT_METHOD = LAST;\n",
+	})
+	parse("UnionWithoutTables", &Spec{
+		Input: "CREATE TABLE total (a INT NOT NULL) ENGINE=MERGE UNION=()",
+		Error: true,
+	})
+	parse("PartitionByUnknownType", &Spec{
+		Input: "CREATE TABLE t (id INT NOT NULL) PARTITION BY FOO (id)",
+		Error: true,
 	})
 	parse("WhiteSpacesBetweenTableOptionsAndSemicolon", &Spec{
 		Input: "CREATE TABLE foo (id INT(10) NOT NULL) ENGINE = InnoDB, DEFAULT CHARACTER SET = utf8mb4 \n/**/ ;",
@@ -536,4 +732,230 @@ primary key (id, c)
 			"`id` INT (10) NOT NULL\n" +
 			") ENGINE = InnoDB, DEFAULT CHARACTER SET = utf8mb4;\n",
 	})
+
+	parse("CreateView", &Spec{
+		Input:  "create view v as select id from foo",
+		Expect: "CREATE VIEW `v` AS select id from foo;\n",
+	})
+	parse("CreateViewWithOptions", &Spec{
+		Input: "CREATE OR REPLACE ALGORITHM=MERGE DEFINER=`root`@`%` SQL SECURITY INVOKER VIEW `v` (a, `b`) AS\n" +
+			"  SELECT a, b FROM foo WHERE c = 'x;y'\n" +
+			"  WITH LOCAL CHECK OPTION;",
+		Expect: "CREATE OR REPLACE ALGORITHM = MERGE DEFINER = `root`@`%` SQL SECURITY INVOKER VIEW `v` (`a`, `b`) AS SELECT a, b FROM foo WHERE c = 'x;y' WITH LOCAL CHECK OPTION;\n",
+	})
+	parse("CreateViewWithCurrentUser", &Spec{
+		Input:  "CREATE DEFINER = CURRENT_USER() VIEW v AS SELECT 1 WITH CHECK OPTION",
+		Expect: "CREATE DEFINER = CURRENT_USER VIEW `v` AS SELECT 1 WITH CASCADED CHECK OPTION;\n",
+	})
+	parse("ViewKeywordsAsColumnNames", &Spec{
+		Input:  "create table foo (view int, algorithm int, definer int, security int, sql int)",
+		Expect: "CREATE TABLE `foo` (\n`view` INT (11) DEFAULT NULL,\n`algorithm` INT (11) DEFAULT NULL,\n`definer` INT (11) DEFAULT NULL,\n`security` INT (11) DEFAULT NULL,\n`sql` INT (11) DEFAULT NULL\n);\n",
+	})
+	parse("CreateViewWithoutSelect", &Spec{
+		Input: "CREATE VIEW v AS ;",
+		Error: true,
+	})
+	parse("CreateProcedure", &Spec{
+		Input: "DELIMITER ;;\n" +
+			"CREATE DEFINER=`root`@`%` PROCEDURE `p`(IN a INT, OUT b VARCHAR(10))\n" +
+			"    READS SQL DATA\n" +
+			"    COMMENT 'it''s'\n" +
+			"BEGIN\n" +
+			"  IF a > 0 THEN SET b = 'x;y'; END IF;\n" +
+			"  CASE a WHEN 1 THEN SET b = 'a'; ELSE SET b = 'b'; END CASE;\n" +
+			"END ;;\n" +
+			"DELIMITER ;\n" +
+			"CREATE PROCEDURE q() SELECT 1;",
+		Expect: "CREATE DEFINER = `root`@`%` PROCEDURE `p` (IN a INT, OUT b VARCHAR(10)) COMMENT 'it''s' READS SQL DATA\n" +
+			"BEGIN\n" +
+			"  IF a > 0 THEN SET b = 'x;y'; END IF;\n" +
+			"  CASE a WHEN 1 THEN SET b = 'a'; ELSE SET b = 'b'; END CASE;\n" +
+			"END;\n" +
+			"CREATE PROCEDURE `q` ()\n" +
+			"SELECT 1;\n",
+	})
+	parse("CreateFunction", &Spec{
+		Input: "DELIMITER //\n" +
+			"CREATE FUNCTION IF NOT EXISTS f (a INT) RETURNS varchar(10) CHARSET utf8mb4\n" +
+			"  DETERMINISTIC NO SQL SQL SECURITY INVOKER\n" +
+			"BEGIN\n" +
+			"  RETURN CASE WHEN a > 0 THEN 'positive' ELSE 'negative' END;\n" +
+			"END//\n" +
+			"DELIMITER ;\n",
+		Expect: "CREATE FUNCTION IF NOT EXISTS `f` (a INT) RETURNS varchar(10) CHARSET utf8mb4 DETERMINISTIC NO SQL SQL SECURITY INVOKER\n" +
+			"BEGIN\n" +
+			"  RETURN CASE WHEN a > 0 THEN 'positive' ELSE 'negative' END;\n" +
+			"END;\n",
+	})
+	parse("CreateFunctionInExecutableComment", &Spec{
+		Input: "DELIMITER ;;\n" +
+			"/*!50003 CREATE*/ /*!50020 DEFINER=`root`@`localhost`*/ /*!50003 FUNCTION `f`() RETURNS int\n" +
+			"    NO SQL\n" +
+			"RETURN 1 */;;\n" +
+			"DELIMITER ;\n",
+		Expect: "CREATE DEFINER = `root`@`localhost` FUNCTION `f` () RETURNS int NO SQL\n" +
+			"RETURN 1;\n",
+	})
+	parse("CreateProcedureWithLoop", &Spec{
+		Input: "CREATE PROCEDURE p() lbl: LOOP LEAVE lbl; END LOOP lbl;\n" +
+			"CREATE PROCEDURE q() SELECT 1",
+		Expect: "CREATE PROCEDURE `p` ()\n" +
+			"lbl: LOOP LEAVE lbl; END LOOP lbl;\n" +
+			"CREATE PROCEDURE `q` ()\n" +
+			"SELECT 1;\n",
+	})
+	parse("CreateProcedureWithIf", &Spec{
+		Input: "CREATE PROCEDURE p() IF 1 THEN SELECT 1; ELSE SELECT IF(1, 2, 3); END IF;\n" +
+			"CREATE PROCEDURE q() SELECT 1",
+		Expect: "CREATE PROCEDURE `p` ()\n" +
+			"IF 1 THEN SELECT 1; ELSE SELECT IF(1, 2, 3); END IF;\n" +
+			"CREATE PROCEDURE `q` ()\n" +
+			"SELECT 1;\n",
+	})
+	parse("CreateProcedureWithWhileAndRepeat", &Spec{
+		Input: "CREATE PROCEDURE p() WHILE @i < 3 DO SET @i = @i + 1; REPEAT SET @j = REPEAT('a', @i); UNTIL @j = '' END REPEAT; END WHILE;\n" +
+			"CREATE PROCEDURE q() SELECT 1",
+		Expect: "CREATE PROCEDURE `p` ()\n" +
+			"WHILE @i < 3 DO SET @i = @i + 1; REPEAT SET @j = REPEAT('a', @i); UNTIL @j = '' END REPEAT; END WHILE;\n" +
+			"CREATE PROCEDURE `q` ()\n" +
+			"SELECT 1;\n",
+	})
+	parse("CreateProcedureWithDelimiter", &Spec{
+		Input: "DELIMITER //\n" +
+			"CREATE PROCEDURE p() IF 1 THEN SELECT 1; END IF //\n" +
+			"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO lbl: LOOP DELETE FROM log LIMIT 100; LEAVE lbl; END LOOP lbl//\n" +
+			"DELIMITER ;\n" +
+			"CREATE PROCEDURE q() SELECT 1;",
+		Expect: "CREATE PROCEDURE `p` ()\n" +
+			"IF 1 THEN SELECT 1; END IF;\n" +
+			"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY\n" +
+			"DO lbl: LOOP DELETE FROM log LIMIT 100; LEAVE lbl; END LOOP lbl;\n" +
+			"CREATE PROCEDURE `q` ()\n" +
+			"SELECT 1;\n",
+	})
+	parse("CreateFunctionWithoutReturns", &Spec{
+		Input: "CREATE FUNCTION f () RETURN 1",
+		Error: true,
+	})
+	parse("CreateProcedureWithoutBody", &Spec{
+		Input: "CREATE PROCEDURE p () DETERMINISTIC;",
+		Error: true,
+	})
+	parse("CreateTrigger", &Spec{
+		Input: "DELIMITER ;;\n" +
+			"CREATE DEFINER=`root`@`%` TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW\n" +
+			"BEGIN\n" +
+			"  IF NEW.a < 0 THEN SET NEW.a = 0; END IF;\n" +
+			"END;;\n" +
+			"DELIMITER ;\n" +
+			"create trigger if not exists hoge_au after update on hoge for each row follows hoge_bi insert into log (id) values (NEW.id)",
+		Expect: "CREATE DEFINER = `root`@`%` TRIGGER `hoge_bi` BEFORE INSERT ON `hoge` FOR EACH ROW\n" +
+			"BEGIN\n" +
+			"  IF NEW.a < 0 THEN SET NEW.a = 0; END IF;\n" +
+			"END;\n" +
+			"CREATE TRIGGER IF NOT EXISTS `hoge_au` AFTER UPDATE ON `hoge` FOR EACH ROW FOLLOWS `hoge_bi`\n" +
+			"insert into log (id) values (NEW.id);\n",
+	})
+	parse("CreateTriggerWithoutEvent", &Spec{
+		Input: "CREATE TRIGGER t BEFORE ON hoge FOR EACH ROW SET NEW.a = 1",
+		Error: true,
+	})
+	parse("CreateEvent", &Spec{
+		Input: "CREATE DEFINER=`root`@`localhost` EVENT `purge_log` ON SCHEDULE EVERY 1 DAY STARTS '2024-01-01 00:00:00' " +
+			"ON COMPLETION NOT PRESERVE ENABLE COMMENT 'purge' DO DELETE FROM log WHERE created < NOW() - INTERVAL 30 DAY;\n" +
+			"create event if not exists e on schedule at (current_timestamp + interval 1 hour) on completion preserve disable on slave\n" +
+			"do begin delete from log; delete from log2; end",
+		Expect: "CREATE DEFINER = `root`@`localhost` EVENT `purge_log` ON SCHEDULE EVERY 1 DAY STARTS '2024-01-01 00:00:00' ENABLE COMMENT 'purge'\n" +
+			"DO DELETE FROM log WHERE created < NOW() - INTERVAL 30 DAY;\n" +
+			"CREATE EVENT IF NOT EXISTS `e` ON SCHEDULE at (current_timestamp + interval 1 hour) ON COMPLETION PRESERVE DISABLE ON SLAVE\n" +
+			"DO begin delete from log; delete from log2; end;\n",
+	})
+	parse("CreateEventWithoutSchedule", &Spec{
+		Input: "CREATE EVENT e ON SCHEDULE DO DELETE FROM log",
+		Error: true,
+	})
+	parse("GeneratedColumns", &Spec{
+		Input: "CREATE TABLE foo (a INT NOT NULL, b INT AS (a * 2), c INT GENERATED ALWAYS AS (a + b) STORED NOT NULL)",
+		Expect: "CREATE TABLE `foo` (\n" +
+			"`a` INT (11) NOT NULL,\n" +
+			"`b` INT (11) GENERATED ALWAYS AS (a * 2) VIRTUAL,\n" +
+			"`c` INT (11) GENERATED ALWAYS AS (a + b) STORED NOT NULL\n" +
+			");\n",
+	})
+	parse("GeneratedKeywordsAsColumnNames", &Spec{
+		Input: "CREATE TABLE t (stored INT, virtual INT, generated INT, always INT AS (stored + virtual) STORED)",
+		Expect: "CREATE TABLE `t` (\n" +
+			"`stored` INT (11) DEFAULT NULL,\n" +
+			"`virtual` INT (11) DEFAULT NULL,\n" +
+			"`generated` INT (11) DEFAULT NULL,\n" +
+			"`always` INT (11) GENERATED ALWAYS AS (stored + virtual) STORED\n" +
+			");\n",
+	})
+	parse("GeneratedColumnWithoutExpression", &Spec{
+		Input: "CREATE TABLE foo (a INT NOT NULL, b INT AS ())",
+		Error: true,
+	})
+	parse("CheckConstraints", &Spec{
+		Input: "CREATE TABLE foo (a INT NOT NULL CHECK (a > 0), CONSTRAINT `a_max` CHECK (a < 100) NOT ENFORCED, INDEX (a))",
+		Expect: "CREATE TABLE `foo` (\n" +
+			"`a` INT (11) NOT NULL,\n" +
+			"INDEX (`a`),\n" +
+			"CONSTRAINT `foo_chk_1` CHECK (a > 0),\n" +
+			"CONSTRAINT `a_max` CHECK (a < 100) NOT ENFORCED\n" +
+			");\n",
+	})
+	parse("EnforcedAsColumnName", &Spec{
+		Input: "CREATE TABLE foo (enforced INT CHECK (enforced > 0) ENFORCED)",
+		Expect: "CREATE TABLE `foo` (\n" +
+			"`enforced` INT (11) DEFAULT NULL,\n" +
+			"CONSTRAINT `foo_chk_1` CHECK (enforced > 0)\n" +
+			");\n",
+	})
+	parse("CheckConstraintWithoutExpression", &Spec{
+		Input: "CREATE TABLE foo (a INT NOT NULL, CHECK ())",
+		Error: true,
+	})
+}
+
+func TestFormatWithServerVersion(t *testing.T) {
+	const input = "create table hoge ( a int, b tinyint(1), c int(5) zerofill, d bigint(20) unsigned )"
+	tests := []struct {
+		version model.ServerVersion
+		want    string
+	}{
+		{
+			version: model.ServerVersionMySQL57,
+			want: "CREATE TABLE `hoge` (\n" +
+				"`a` INT (11) DEFAULT NULL,\n" +
+				"`b` TINYINT (1) DEFAULT NULL,\n" +
+				"`c` INT (5) ZEROFILL DEFAULT NULL,\n" +
+				"`d` BIGINT (20) UNSIGNED DEFAULT NULL\n" +
+				");\n",
+		},
+		{
+			version: model.ServerVersionMySQL80,
+			want: "CREATE TABLE `hoge` (\n" +
+				"`a` INT DEFAULT NULL,\n" +
+				"`b` TINYINT (1) DEFAULT NULL,\n" +
+				"`c` INT (5) ZEROFILL DEFAULT NULL,\n" +
+				"`d` BIGINT UNSIGNED DEFAULT NULL\n" +
+				");\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version.String(), func(t *testing.T) {
+			p := schemalex.New()
+			stmts, err := p.ParseString(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf strings.Builder
+			if err := SQL(&buf, stmts, WithServerVersion(tt.version)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("(-want/+got):\n%s", diff)
+			}
+		})
+	}
 }
@@ -1,9 +1,14 @@
 package format
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/model"
+)
 
 type myOptions struct {
-	indent string
+	indent  string
+	version *model.ServerVersion
 }
 
 type Option interface {
@@ -28,3 +33,18 @@ func WithIndent(s string, n int) Option {
 	}
 	return withIndent(strings.Repeat(s, n))
 }
+
+type withServerVersion model.ServerVersion
+
+func (opt withServerVersion) apply(opts *myOptions) {
+	v := model.ServerVersion(opt)
+	opts.version = &v
+}
+
+// WithServerVersion specifies the version of the server that runs the statements.
+// For example, the display widths of integer types are omitted for MySQL 8.0.19 and later,
+// because they are deprecated.
+// If unspecified, the statements are compatible with all versions.
+func WithServerVersion(v model.ServerVersion) Option {
+	return withServerVersion(v)
+}
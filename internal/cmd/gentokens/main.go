@@ -93,6 +93,7 @@ func _main() error {
 		{Ident: "RPAREN", Comment: ")"},
 		{Ident: "COMMA", Comment: ","},
 		{Ident: "SEMICOLON", Comment: ";"},
+		{Ident: "DELIMITER", Comment: "the delimiter changed by the DELIMITER command, such as ;; and //"},
 		{Ident: "DOT", Comment: "."},
 		{Ident: "SLASH", Comment: "/"},
 		{Ident: "ASTERISK", Comment: "*"},
@@ -101,9 +102,11 @@ func _main() error {
 		{Ident: "SINGLE_QUOTE", Comment: "'"},
 		{Ident: "DOUBLE_QUOTE", Comment: "\""},
 		{Ident: "EQUAL", Comment: "="},
+		{Ident: "ATMARK", Comment: "@"},
 		{Ident: "COMMENT_IDENT", Comment: `// /*   */, --, #`},
 
 		{Ident: "ACTION"},
+		{Ident: "AS"},
 		{Ident: "ASC"},
 		{Ident: "AUTO_INCREMENT"},
 		{Ident: "AVG_ROW_LENGTH"},
@@ -184,6 +187,7 @@ func _main() error {
 		{Ident: "NULL"},
 		{Ident: "NUMERIC"},
 		{Ident: "ON"},
+		{Ident: "OR"},
 		{Ident: "PACK_KEYS"},
 		{Ident: "PARSER"},
 		{Ident: "PARTIAL"},
@@ -194,6 +198,7 @@ func _main() error {
 		{Ident: "REAL"},
 		{Ident: "REDUNDANT"},
 		{Ident: "REFERENCES"},
+		{Ident: "REPLACE"},
 		{Ident: "RESTRICT"},
 		{Ident: "ROW_FORMAT"},
 		{Ident: "SET"},
@@ -238,7 +243,7 @@ func _main() error {
 	println(")", "") // end const (
 
 	println("var keywordIdentMap = map[string]TokenType{")
-	for _, tok := range tokens[20:] {
+	for _, tok := range tokens[22:] {
 		println(strconv.Quote(tok.Ident) + ": " + tok.Ident + ",")
 	}
 	println("}", "")
@@ -1,6 +1,9 @@
 package util
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
 // Backquote surrounds the given string in backquotes
 func Backquote(s string) string {
@@ -19,3 +22,83 @@ func Backquote(s string) string {
 	buf.WriteByte('`')
 	return buf.String()
 }
+
+// NormalizeSQL normalizes the SQL text for comparison.
+// It collapses white spaces, converts the text outside of quotes to lower case,
+// and removes backquotes around plain identifiers.
+// e.g. "SELECT `id`  FROM `hoge`" and "select id from hoge" are normalized to same text.
+func NormalizeSQL(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	rs := []rune(s)
+	space := false
+	var last rune
+	write := func(str string) {
+		first, _ := utf8.DecodeRuneInString(str)
+		if space && buf.Len() > 0 && !isSQLPunct(last) && !isSQLPunct(first) {
+			buf.WriteByte(' ')
+		}
+		space = false
+		buf.WriteString(str)
+		last, _ = utf8.DecodeLastRuneInString(str)
+	}
+
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			space = true
+		case '\'', '"', '`':
+			// find the end of the quote.
+			j := i + 1
+			for ; j < len(rs); j++ {
+				if rs[j] == '\\' && r != '`' {
+					j++
+					continue
+				}
+				if rs[j] == r {
+					if j+1 < len(rs) && rs[j+1] == r {
+						// escaped quote
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(rs) {
+				j = len(rs) - 1
+			}
+			quoted := string(rs[i : j+1])
+			if r == '`' && isPlainIdent(quoted[1:len(quoted)-1]) {
+				quoted = strings.ToLower(quoted[1 : len(quoted)-1])
+			}
+			write(quoted)
+			i = j
+		default:
+			write(strings.ToLower(string(r)))
+		}
+	}
+	return buf.String()
+}
+
+func isSQLPunct(r rune) bool {
+	switch r {
+	case '(', ')', ',', '.', '=', ';':
+		return true
+	}
+	return false
+}
+
+// isPlainIdent returns whether s can be used as an identifier without quotes.
+func isPlainIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '$') {
+			return false
+		}
+	}
+	return true
+}
@@ -11,3 +11,25 @@ func TestUtil(t *testing.T) {
 		t.Errorf("want %q, got %q", want, got)
 	}
 }
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"SELECT `id`  FROM `hoge`", "select id from hoge"},
+		{"select\n  count( * )\nfrom hoge", "SELECT COUNT(*) FROM hoge"},
+		{"select `hoge`.`id` AS `id` from `hoge`", "select hoge.id as id from hoge"},
+		{"select 'A  B'", "SELECT 'A  B'"},
+		{"select 'it''s'", "SELECT 'it''s'"},
+		{"select `a b`", "SELECT `a b`"},
+	}
+	for _, tt := range tests {
+		if a, b := NormalizeSQL(tt.a), NormalizeSQL(tt.b); a != b {
+			t.Errorf("NormalizeSQL(%q) = %q, NormalizeSQL(%q) = %q", tt.a, a, tt.b, b)
+		}
+	}
+
+	if a, b := NormalizeSQL("select 'A'"), NormalizeSQL("select 'a'"); a == b {
+		t.Errorf("quoted strings should not be normalized: %q", a)
+	}
+}
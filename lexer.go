@@ -29,6 +29,13 @@ type lexer struct {
 	start position // position where we last emitted
 	cur   position // current position including read-ahead
 	width int
+
+	// executable is true in the executable comment, such as `/*!50100 PARTITION BY ... */`.
+	executable bool
+
+	// delimiter is the statement delimiter changed by the DELIMITER command, such as `;;` and `//`.
+	// It is empty if the default delimiter `;` is used.
+	delimiter string
 }
 
 func lex(input []byte) []*Token {
@@ -90,6 +97,11 @@ func (l *lexer) str() string {
 func (l *lexer) run() {
 OUTER:
 	for {
+		if l.runDelimiter() {
+			l.emit(DELIMITER)
+			continue OUTER
+		}
+
 		r := l.peek()
 
 		// These require peek, and then consume
@@ -102,6 +114,11 @@ OUTER:
 		case isLetter(r):
 			t := l.runIdent()
 			s := l.str()
+			if strings.EqualFold(s, "DELIMITER") && l.runDelimiterCommand() {
+				// the DELIMITER command is not a SQL statement.
+				l.emit(SPACE)
+				continue OUTER
+			}
 			if typ, ok := keywordIdentMap[strings.ToUpper(s)]; ok {
 				t = typ
 			}
@@ -143,11 +160,27 @@ OUTER:
 		case '/':
 			switch c := l.peek(); c {
 			case '*':
+				if !l.executable && l.runExecutableComment() {
+					// MySQL executes the statements in the executable comments,
+					// so we treat the comment markers as spaces.
+					l.executable = true
+					l.emit(SPACE)
+					continue OUTER
+				}
 				l.runCComment()
 				l.emit(COMMENT_IDENT)
 			default:
 				l.emit(SLASH)
 			}
+		case '*':
+			if l.executable && l.peek() == '/' {
+				// the end of the executable comment
+				l.advance()
+				l.executable = false
+				l.emit(SPACE)
+				continue OUTER
+			}
+			l.emit(ASTERISK)
 		case '-':
 			switch r1 := l.peek(); {
 			case r1 == '-':
@@ -159,6 +192,10 @@ OUTER:
 					continue OUTER
 				}
 				l.runToEOL()
+				if isFileAnnotation(l.str()) {
+					// the delimiter doesn't continue to the next file.
+					l.delimiter = ""
+				}
 				l.emit(COMMENT_IDENT)
 			case isDigit(r1):
 				l.runNumber()
@@ -194,6 +231,8 @@ OUTER:
 			}
 		case '=':
 			l.emit(EQUAL)
+		case '@':
+			l.emit(ATMARK)
 		default:
 			l.emit(ILLEGAL)
 		}
@@ -325,6 +364,105 @@ func (l *lexer) runCComment() {
 	}
 }
 
+// runExecutableComment reads the beginning of the executable comment, such as `/*!50100` and `/*M!100100`.
+// It returns false if the comment is not executable, and it reads nothing in that case.
+// The statements other than CREATE in the executable comments, such as `/*!40000 ALTER TABLE t DISABLE KEYS */`,
+// are not supported, so they are left as comments and skipped.
+// https://dev.mysql.com/doc/refman/8.0/en/comments.html
+// https://mariadb.com/kb/en/comment-syntax/
+func (l *lexer) runExecutableComment() bool {
+	// l.cur.pos points to the next rune of '*'.
+	rest := l.input[l.cur.pos:]
+	var n int
+	switch {
+	case bytes.HasPrefix(rest, []byte("!")):
+		n = 1
+	case bytes.HasPrefix(rest, []byte("M!")):
+		n = 2
+	default:
+		return false
+	}
+	if l.atStatementStart() {
+		body := bytes.TrimLeft(bytes.TrimLeft(rest[n:], "0123456789"), " \t\r\n")
+		if len(body) < len("CREATE") || !bytes.EqualFold(body[:len("CREATE")], []byte("CREATE")) {
+			return false
+		}
+	}
+
+	l.advance() // '*'
+	for i := 0; i < n; i++ {
+		l.next()
+	}
+	l.runDigit() // the version number
+	return true
+}
+
+// runDelimiter reads the delimiter changed by the DELIMITER command.
+// It returns false if the delimiter is not changed or the input doesn't start with the delimiter.
+func (l *lexer) runDelimiter() bool {
+	if l.delimiter == "" {
+		return false
+	}
+	pos := l.cur.pos - (l.peekCount + 1)
+	if !bytes.HasPrefix(l.input[pos:], []byte(l.delimiter)) {
+		return false
+	}
+	for end := pos + len(l.delimiter); l.cur.pos-(l.peekCount+1) < end; {
+		l.next()
+	}
+	return true
+}
+
+// runDelimiterCommand reads the rest of the DELIMITER command of the mysql client, such as `DELIMITER ;;`.
+// l.start points to `DELIMITER`.
+// The command is only recognized at the beginning of a line and a statement,
+// otherwise it returns false and it reads nothing.
+// https://dev.mysql.com/doc/refman/8.0/en/mysql-commands.html
+func (l *lexer) runDelimiterCommand() bool {
+	for i := l.start.pos - 1; i >= 0 && l.input[i] != '\n'; i-- {
+		if l.input[i] != ' ' && l.input[i] != '\t' {
+			return false
+		}
+	}
+	if !l.atStatementStart() {
+		return false
+	}
+
+	// the new delimiter is the first word of the rest of the line.
+	pos := l.cur.pos - (l.peekCount + 1)
+	line := l.input[pos:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return false
+	}
+
+	l.runToEOL()
+	if fields[0] == ";" {
+		l.delimiter = ""
+	} else {
+		l.delimiter = fields[0]
+	}
+	return true
+}
+
+// atStatementStart returns whether no token except spaces and comments is emitted in the current statement.
+func (l *lexer) atStatementStart() bool {
+	for i := len(l.out) - 1; i >= 0; i-- {
+		if typ := l.out[i].Type; typ != SPACE && typ != COMMENT_IDENT {
+			return typ == SEMICOLON || typ == DELIMITER
+		}
+	}
+	return true
+}
+
+// isDelimiterCommand returns whether the token is the DELIMITER command read by runDelimiterCommand.
+func isDelimiterCommand(t *Token) bool {
+	return t.Type == SPACE && len(t.Value) >= len("DELIMITER") && strings.EqualFold(t.Value[:len("DELIMITER")], "DELIMITER")
+}
+
 func (l *lexer) runToEOL() TokenType {
 	for {
 		r := l.next()
@@ -107,3 +107,51 @@ func TestLexToken(t *testing.T) {
 		})
 	}
 }
+
+func TestLexDelimiter(t *testing.T) {
+	input := "DELIMITER $$\nSELECT 1; SELECT 2$$\n  delimiter ;\nSELECT 3;"
+	var got []TokenType
+	for _, tok := range lex([]byte(input)) {
+		if tok.Type != SPACE {
+			got = append(got, tok.Type)
+		}
+	}
+	want := []TokenType{
+		IDENT, NUMBER, SEMICOLON, IDENT, NUMBER, DELIMITER,
+		IDENT, NUMBER, SEMICOLON,
+		EOF,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("tokens mismatch: (-want/+got):\n%s", diff)
+	}
+
+	// DELIMITER in the middle of a statement is not the command.
+	input = "CREATE TABLE t (\ndelimiter INT\n)"
+	var found bool
+	for _, tok := range lex([]byte(input)) {
+		if tok.Type == IDENT && tok.Value == "delimiter" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("want IDENT delimiter, but not found")
+	}
+
+	// the file annotation resets the delimiter.
+	input = "DELIMITER $$\nSELECT 1$$\n-- schemalex:file b.sql\nSELECT 2$$"
+	got = got[:0]
+	for _, tok := range lex([]byte(input)) {
+		if tok.Type != SPACE {
+			got = append(got, tok.Type)
+		}
+	}
+	want = []TokenType{
+		IDENT, NUMBER, DELIMITER,
+		COMMENT_IDENT,
+		IDENT, NUMBER, ILLEGAL, ILLEGAL,
+		EOF,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("tokens mismatch: (-want/+got):\n%s", diff)
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import "strings"
+
+// Check describes a CHECK constraint on a table.
+type Check struct {
+	Name        MaybeIdent
+	Expr        string // the expression without the surrounding parentheses
+	NotEnforced bool
+}
+
+// NewCheck creates a new CHECK constraint with the given expression
+func NewCheck(expr string) *Check {
+	return &Check{
+		Expr: expr,
+	}
+}
+
+func (c *Check) ID() string {
+	return "check#" + strings.ToLower(string(c.Name.Ident))
+}
@@ -4,8 +4,11 @@ import "strings"
 
 // Database represents a database definition
 type Database struct {
-	Name        Ident
-	IfNotExists bool
+	Name         Ident
+	IfNotExists  bool
+	CharacterSet MaybeIdent
+	Collation    MaybeIdent
+	Encryption   MaybeString // 'Y' or 'N'
 }
 
 // NewDatabase creates a new database mode with th given name
@@ -18,3 +21,28 @@ func NewDatabase(name Ident) *Database {
 func (d *Database) ID() string {
 	return "database#" + strings.ToLower(string(d.Name))
 }
+
+// Canonical returns the canonical form of the database options on the server version.
+// It is intended to compare databases, like TableColumn.Canonical.
+func (d *Database) Canonical(v ServerVersion) *Database {
+	db := *d
+	if db.CharacterSet.Valid {
+		db.CharacterSet.Ident = Ident(canonicalCharset(string(db.CharacterSet.Ident)))
+	}
+	if db.Collation.Valid {
+		db.Collation.Ident = Ident(canonicalCollation(string(db.Collation.Ident)))
+
+		// the name of the collation starts with its character set.
+		if !db.CharacterSet.Valid {
+			charset, _, _ := strings.Cut(string(db.Collation.Ident), "_")
+			db.CharacterSet = MaybeIdent{Ident: Ident(charset), Valid: true}
+		}
+		if string(db.Collation.Ident) == v.DefaultCollation(string(db.CharacterSet.Ident)) {
+			db.Collation = MaybeIdent{}
+		}
+	}
+	if db.Encryption.Valid {
+		db.Encryption.Value = strings.ToUpper(db.Encryption.Value)
+	}
+	return &db
+}
@@ -0,0 +1,30 @@
+package model
+
+import "strings"
+
+// Event describes a scheduled event definition
+type Event struct {
+	Name        Ident
+	IfNotExists bool
+	Definer     string // e.g. `root`@`localhost` or CURRENT_USER
+
+	// Schedule is the schedule of the event after `ON SCHEDULE`,
+	// such as `AT CURRENT_TIMESTAMP + INTERVAL 1 HOUR` and `EVERY 1 DAY STARTS '2024-01-01 00:00:00'`.
+	Schedule string
+
+	Preserve bool   // ON COMPLETION PRESERVE
+	Status   string // ENABLE, DISABLE or DISABLE ON REPLICA
+	Comment  MaybeString
+	Body     string // the statement after `DO`
+}
+
+// NewEvent creates a new event with the given name
+func NewEvent(name Ident) *Event {
+	return &Event{
+		Name: name,
+	}
+}
+
+func (e *Event) ID() string {
+	return "event#" + strings.ToLower(string(e.Name))
+}
@@ -29,6 +29,15 @@ func (ident Ident) Quoted() string {
 	return buf.String()
 }
 
+// qualifiedName returns the quoted name qualified with the schema.
+// The schema is omitted if it is empty.
+func qualifiedName(schema, name Ident) string {
+	if schema == "" {
+		return name.Quoted()
+	}
+	return schema.Quoted() + "." + name.Quoted()
+}
+
 // MaybeIdent is an Ident that may not be set.
 type MaybeIdent struct {
 	Ident
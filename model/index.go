@@ -7,6 +7,8 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/internal/util"
 )
 
 type IndexColumnSortDirection int
@@ -51,6 +53,9 @@ type Index struct {
 	Columns        []*IndexColumn
 	Reference      *Reference
 	Options        []*IndexOption
+
+	// Invisible is true if the index is not used by the optimizer.
+	Invisible bool
 }
 
 // NewIndex creates a new index with the given index kind.
@@ -107,6 +112,10 @@ type IndexColumn struct {
 	Name          Ident
 	Length        MaybeString
 	SortDirection IndexColumnSortDirection
+
+	// Expr is the expression of a functional key part, such as `(lower(email))`,
+	// without the surrounding parentheses. Name is empty if Expr is valid.
+	Expr MaybeString
 }
 
 func NewIndexColumn(name Ident) *IndexColumn {
@@ -115,7 +124,18 @@ func NewIndexColumn(name Ident) *IndexColumn {
 	}
 }
 
+// NewIndexExprColumn creates a new functional key part with the given expression.
+func NewIndexExprColumn(expr string) *IndexColumn {
+	return &IndexColumn{
+		Expr: MaybeString{Valid: true, Value: expr},
+	}
+}
+
 func (col *IndexColumn) ID() string {
+	if col.Expr.Valid {
+		// the expressions that differ only in white spaces, case and quotes are same.
+		return "index_column#(" + util.NormalizeSQL(col.Expr.Value) + ")"
+	}
 	name := strings.ToLower(string(col.Name))
 	if col.Length.Valid {
 		return "index_column#" + name + "-" + col.Length.Value
@@ -0,0 +1,92 @@
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=PartitionType -linecomment -output=partition_type_string_gen.go
+
+package model
+
+import "strings"
+
+// PartitionType describes the type of partitioning.
+type PartitionType int
+
+// List of possible PartitionType values.
+const (
+	PartitionTypeNone         PartitionType = iota // NONE
+	PartitionTypeRange                             // RANGE
+	PartitionTypeRangeColumns                      // RANGE COLUMNS
+	PartitionTypeList                              // LIST
+	PartitionTypeListColumns                       // LIST COLUMNS
+	PartitionTypeHash                              // HASH
+	PartitionTypeKey                               // KEY
+)
+
+// Partition describes the partitioning of a table, such as `PARTITION BY RANGE (expr) (...)`.
+type Partition struct {
+	Type   PartitionType
+	Linear bool
+
+	// Expr is the expression of RANGE, LIST and HASH partitioning,
+	// without the surrounding parentheses.
+	Expr string
+
+	// Columns are the columns of RANGE COLUMNS, LIST COLUMNS and KEY partitioning.
+	Columns []Ident
+
+	// Algorithm is the hash algorithm of KEY partitioning, `ALGORITHM={1|2}`.
+	Algorithm MaybeString
+
+	// Num is the number of the partitions, `PARTITIONS num`.
+	Num MaybeString
+
+	// SubPartition is the subpartitioning, `SUBPARTITION BY ...`.
+	// Its Num is the number of the subpartitions, and its Definitions are not used.
+	SubPartition *Partition
+
+	Definitions []*PartitionDefinition
+}
+
+// PartitionDefinition describes a partition or a subpartition.
+type PartitionDefinition struct {
+	Name Ident
+
+	// Values is the range or the list of the values, such as `LESS THAN (10)`, `LESS THAN MAXVALUE` and `IN (1, 2)`.
+	Values MaybeString
+
+	// Options are the options of the partition, such as `ENGINE = InnoDB` and `COMMENT = 'foo'`.
+	Options []*TableOption
+
+	SubPartitions []*PartitionDefinition
+}
+
+// NewPartition creates a new partitioning with the given type.
+func NewPartition(typ PartitionType) *Partition {
+	return &Partition{
+		Type: typ,
+	}
+}
+
+// NewPartitionDefinition creates a new partition with the given name.
+func NewPartitionDefinition(name Ident) *PartitionDefinition {
+	return &PartitionDefinition{
+		Name: name,
+	}
+}
+
+// ID returns the ID of the partitioning.
+// A table has at most one partitioning, so the ID is always same.
+func (p *Partition) ID() string {
+	return "partition"
+}
+
+// ID returns the ID of the partition.
+func (def *PartitionDefinition) ID() string {
+	return "partition#" + strings.ToLower(string(def.Name))
+}
+
+// LookupDefinition looks for the partition with the given ID.
+func (p *Partition) LookupDefinition(id string) (*PartitionDefinition, bool) {
+	for _, def := range p.Definitions {
+		if def.ID() == id {
+			return def, true
+		}
+	}
+	return nil, false
+}
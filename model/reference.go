@@ -34,6 +34,10 @@ const (
 
 // Reference describes a possible reference from one table to another
 type Reference struct {
+	// TableSchema is the database of the referenced table.
+	// It is empty if the referenced table belongs to the same database as the referencing table.
+	TableSchema Ident
+
 	TableName Ident
 	Columns   []*IndexColumn
 	Match     ReferenceMatch
@@ -48,6 +52,9 @@ func NewReference() *Reference {
 
 func (r *Reference) ID() string {
 	h := sha256.New()
+	if r.TableSchema != "" {
+		fmt.Fprintf(h, "%s.", r.TableSchema)
+	}
 	fmt.Fprintf(h,
 		"%s.%s.%s.%s",
 		r.TableName,
@@ -61,3 +68,8 @@ func (r *Reference) ID() string {
 	}
 	return fmt.Sprintf("reference#%x", h.Sum(nil))
 }
+
+// QuotedTableName returns the quoted name of the referenced table qualified with the schema, e.g. `db`.`table`.
+func (r *Reference) QuotedTableName() string {
+	return qualifiedName(r.TableSchema, r.TableName)
+}
@@ -0,0 +1,47 @@
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -type=RoutineKind -linecomment -output=routine_kind_string_gen.go
+
+package model
+
+import "strings"
+
+// RoutineKind describes the kind of a stored routine.
+type RoutineKind int
+
+// List of possible RoutineKind values.
+const (
+	RoutineKindProcedure RoutineKind = iota // PROCEDURE
+	RoutineKindFunction                     // FUNCTION
+)
+
+// Routine describes a stored procedure or a stored function.
+type Routine struct {
+	Kind        RoutineKind
+	Name        Ident
+	IfNotExists bool
+	Definer     string // e.g. `root`@`localhost` or CURRENT_USER
+
+	// Params are the parameters without the surrounding parentheses,
+	// such as `IN a INT, OUT b INT` for procedures and `a INT` for functions.
+	Params string
+
+	// Returns is the return type of the function, such as `INT` and `VARCHAR(10) CHARSET utf8mb4`.
+	Returns string
+
+	Comment       MaybeString
+	Deterministic bool
+	DataAccess    string // CONTAINS SQL, NO SQL, READS SQL DATA or MODIFIES SQL DATA
+	SQLSecurity   string // DEFINER or INVOKER
+	Body          string // the routine body, such as `BEGIN ... END`
+}
+
+// NewRoutine creates a new stored routine with the given kind and name
+func NewRoutine(kind RoutineKind, name Ident) *Routine {
+	return &Routine{
+		Kind: kind,
+		Name: name,
+	}
+}
+
+func (r *Routine) ID() string {
+	return strings.ToLower(r.Kind.String()) + "#" + strings.ToLower(string(r.Name))
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Flavor describes the distribution of the database server.
+type Flavor int
+
+// List of possible Flavor values.
+const (
+	FlavorMySQL Flavor = iota
+	FlavorMariaDB
+)
+
+// ServerVersion describes the version of the database server.
+// The canonical form of a column depends on it,
+// e.g. MySQL 8.0.19 and later don't show the display widths of integer types.
+type ServerVersion struct {
+	Flavor Flavor
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+// The well-known server versions.
+var (
+	ServerVersionMySQL57 = ServerVersion{Flavor: FlavorMySQL, Major: 5, Minor: 7, Patch: 44}
+	ServerVersionMySQL80 = ServerVersion{Flavor: FlavorMySQL, Major: 8, Minor: 0, Patch: 40}
+	ServerVersionMySQL84 = ServerVersion{Flavor: FlavorMySQL, Major: 8, Minor: 4, Patch: 0}
+	ServerVersionMariaDB = ServerVersion{Flavor: FlavorMariaDB, Major: 10, Minor: 11, Patch: 0}
+)
+
+// DefaultServerVersion is the server version that is used if it is not specified.
+var DefaultServerVersion = ServerVersionMySQL80
+
+// ParseServerVersion parses the output of SELECT VERSION(), such as "8.0.36", "5.7.44-log"
+// and "10.11.6-MariaDB-1:10.11.6+maria~ubu2204".
+// It also accepts the short names "5.7", "8.0", "8.4" and "mariadb".
+func ParseServerVersion(s string) (ServerVersion, error) {
+	orig := s
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "mariadb" {
+		return ServerVersionMariaDB, nil
+	}
+
+	var v ServerVersion
+	if strings.Contains(s, "mariadb") {
+		v.Flavor = FlavorMariaDB
+		// MariaDB 10 reports "5.5.5-10.x.y-MariaDB" for the replication protocol.
+		s = strings.TrimPrefix(s, "5.5.5-")
+	}
+	if i := strings.IndexFunc(s, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ServerVersion{}, fmt.Errorf("invalid server version: %q", orig)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return ServerVersion{}, fmt.Errorf("invalid server version: %q", orig)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	if len(parts) == 2 {
+		// the short names mean the latest releases.
+		switch v {
+		case ServerVersion{Flavor: FlavorMySQL, Major: 5, Minor: 7}:
+			return ServerVersionMySQL57, nil
+		case ServerVersion{Flavor: FlavorMySQL, Major: 8, Minor: 0}:
+			return ServerVersionMySQL80, nil
+		}
+	}
+	return v, nil
+}
+
+// String returns the version in the form of "8.0.40" or "10.11.0-MariaDB".
+func (v ServerVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Flavor == FlavorMariaDB {
+		s += "-MariaDB"
+	}
+	return s
+}
+
+// AtLeast returns whether v is the same as or later than major.minor.patch.
+func (v ServerVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// IsMySQL returns whether the server is MySQL, and its version is at least major.minor.patch.
+func (v ServerVersion) IsMySQL(major, minor, patch int) bool {
+	return v.Flavor == FlavorMySQL && v.AtLeast(major, minor, patch)
+}
+
+// DefaultCollation returns the default collation of the character set.
+// It returns an empty string if the character set is unknown.
+func (v ServerVersion) DefaultCollation(charset string) string {
+	charset = canonicalCharset(charset)
+	if charset == "utf8mb4" {
+		if v.IsMySQL(8, 0, 0) {
+			return "utf8mb4_0900_ai_ci"
+		}
+		return "utf8mb4_general_ci"
+	}
+	return defaultCollations[charset]
+}
+
+// SupportsRenameColumn returns whether the server supports ALTER TABLE ... RENAME COLUMN.
+func (v ServerVersion) SupportsRenameColumn() bool {
+	if v.Flavor == FlavorMariaDB {
+		return v.AtLeast(10, 5, 2)
+	}
+	return v.AtLeast(8, 0, 0)
+}
+
+// SupportsRenameIndex returns whether the server supports ALTER TABLE ... RENAME INDEX.
+func (v ServerVersion) SupportsRenameIndex() bool {
+	if v.Flavor == FlavorMariaDB {
+		return v.AtLeast(10, 5, 2)
+	}
+	return v.AtLeast(5, 7, 0)
+}
+
+// SupportsInstantAlter returns whether the server supports ALTER TABLE ... ALGORITHM=INSTANT.
+func (v ServerVersion) SupportsInstantAlter() bool {
+	if v.Flavor == FlavorMariaDB {
+		return v.AtLeast(10, 3, 2)
+	}
+	return v.AtLeast(8, 0, 12)
+}
+
+// SupportsInvisibleColumn returns whether the server supports invisible columns.
+func (v ServerVersion) SupportsInvisibleColumn() bool {
+	if v.Flavor == FlavorMariaDB {
+		return v.AtLeast(10, 3, 3)
+	}
+	return v.AtLeast(8, 0, 23)
+}
+
+// SupportsReplicaKeyword returns whether the server supports REPLICA as the alias of SLAVE,
+// such as CREATE EVENT ... DISABLE ON REPLICA.
+func (v ServerVersion) SupportsReplicaKeyword() bool {
+	return v.IsMySQL(8, 0, 22)
+}
+
+// SupportsDefaultEncryption returns whether the server supports the default encryption of databases,
+// such as CREATE DATABASE ... DEFAULT ENCRYPTION = 'Y'.
+func (v ServerVersion) SupportsDefaultEncryption() bool {
+	return v.IsMySQL(8, 0, 16)
+}
+
+// IgnoresDisplayWidth returns whether the server ignores the display width of the integer column.
+// MySQL 8.0.19 and later deprecate the display widths of integer types,
+// except TINYINT(1) and the columns with ZEROFILL.
+func (v ServerVersion) IgnoresDisplayWidth(col *TableColumn) bool {
+	if !v.IsMySQL(8, 0, 19) || col.ZeroFill || col.Length == nil {
+		return false
+	}
+	switch col.Type {
+	case ColumnTypeTinyInt:
+		return col.Length.Length != "1"
+	case ColumnTypeSmallInt, ColumnTypeMediumInt, ColumnTypeInt, ColumnTypeInteger, ColumnTypeBigInt, ColumnTypeYear:
+		return true
+	}
+	return false
+}
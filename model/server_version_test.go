@@ -0,0 +1,34 @@
+package model
+
+import "testing"
+
+func TestParseServerVersion(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want ServerVersion
+	}{
+		{"8.0.36", ServerVersion{Flavor: FlavorMySQL, Major: 8, Minor: 0, Patch: 36}},
+		{"5.7.44-log", ServerVersion{Flavor: FlavorMySQL, Major: 5, Minor: 7, Patch: 44}},
+		{"8.4.0-0ubuntu0.24.04.1", ServerVersion{Flavor: FlavorMySQL, Major: 8, Minor: 4, Patch: 0}},
+		{"10.11.6-MariaDB-1:10.11.6+maria~ubu2204", ServerVersion{Flavor: FlavorMariaDB, Major: 10, Minor: 11, Patch: 6}},
+		{"5.5.5-10.6.16-MariaDB", ServerVersion{Flavor: FlavorMariaDB, Major: 10, Minor: 6, Patch: 16}},
+		{"8.0", ServerVersionMySQL80},
+		{"mariadb", ServerVersionMariaDB},
+	}
+	for _, tc := range testCases {
+		got, err := ParseServerVersion(tc.in)
+		if err != nil {
+			t.Errorf("ParseServerVersion(%q) returns error: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseServerVersion(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"", "8", "foo"} {
+		if _, err := ParseServerVersion(in); err == nil {
+			t.Errorf("ParseServerVersion(%q) want error, but not", in)
+		}
+	}
+}
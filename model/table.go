@@ -1,16 +1,32 @@
 package model
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 // Table describes a table model
 type Table struct {
+	// Schema is the database that the table belongs to.
+	// It is empty if the table belongs to the current database.
+	Schema Ident
+
 	Name        Ident
 	Temporary   bool
 	IfNotExists bool
 	LikeTable   MaybeIdent
 	Columns     []*TableColumn
 	Indexes     []*Index
+	Checks      []*Check
 	Options     []*TableOption
+
+	// Partition is the partitioning of the table.
+	// It is nil if the table is not partitioned.
+	Partition *Partition
+
+	// RenamedFrom is the previous name of the table.
+	// It is set by the `-- schemalex:renamed-from old_name` annotation.
+	RenamedFrom MaybeIdent
 }
 
 // NewTable create a new table with the given name
@@ -21,9 +37,17 @@ func NewTable(name Ident) *Table {
 }
 
 func (t *Table) ID() string {
+	if t.Schema != "" {
+		return "table#" + strings.ToLower(string(t.Schema)) + "." + strings.ToLower(string(t.Name))
+	}
 	return "table#" + strings.ToLower(string(t.Name))
 }
 
+// QuotedName returns the quoted name of the table qualified with the schema, e.g. `db`.`table`.
+func (t *Table) QuotedName() string {
+	return qualifiedName(t.Schema, t.Name)
+}
+
 func (t *Table) LookupColumn(id string) (*TableColumn, bool) {
 	for _, col := range t.Columns {
 		if col.ID() == id {
@@ -63,9 +87,19 @@ func (t *Table) LookupIndex(id string) (*Index, bool) {
 	return nil, false
 }
 
+func (t *Table) LookupCheck(id string) (*Check, bool) {
+	for _, check := range t.Checks {
+		if check.ID() == id {
+			return check, true
+		}
+	}
+	return nil, false
+}
+
 func (t *Table) Normalize() *Table {
 	var additionalIndexes []*Index
 	var columns []*TableColumn
+	var checks []*Check
 	for _, col := range t.Columns {
 		ncol := col.Normalize()
 
@@ -93,8 +127,14 @@ func (t *Table) Normalize() *Table {
 			ncol.Unique = false
 		}
 
+		// column_definition CHECK (expr)
+		// it means same as CHECK constraint of the table
+		checks = append(checks, ncol.Checks...)
+		ncol.Checks = nil
+
 		columns = append(columns, ncol)
 	}
+	checks = append(checks, t.Checks...)
 
 	var indexes []*Index
 	var seen = make(map[Ident]struct{})
@@ -128,11 +168,48 @@ func (t *Table) Normalize() *Table {
 	tbl.Temporary = t.Temporary
 	tbl.Indexes = append(additionalIndexes, indexes...)
 	tbl.Columns = columns
+	tbl.Checks = nil
+	var n int
+	for _, check := range checks {
+		ncheck := *check
+		if !ncheck.Name.Valid {
+			// if you do not assign a name, MySQL generates a name from the table name,
+			// a literal _chk_, and an ordinal number.
+			n++
+			ncheck.Name.Valid = true
+			ncheck.Name.Ident = Ident(string(t.Name) + "_chk_" + strconv.Itoa(n))
+		}
+		tbl.Checks = append(tbl.Checks, &ncheck)
+	}
 	tbl.Options = make([]*TableOption, len(t.Options))
 	copy(tbl.Options, t.Options)
 	return &tbl
 }
 
+// defaultCharset returns the canonical names of the default character set and collation of the table.
+// They are empty if the table doesn't specify them.
+func (t *Table) defaultCharset(v ServerVersion) (charset, collation string) {
+	if t == nil {
+		return "", ""
+	}
+	for _, opt := range t.Options {
+		switch opt.ID() {
+		case "tableopt#default character set":
+			charset = canonicalCharset(opt.Value)
+		case "tableopt#default collate":
+			collation = canonicalCollation(opt.Value)
+		}
+	}
+	if charset == "" && collation != "" {
+		// the name of the collation starts with its character set.
+		charset, _, _ = strings.Cut(collation, "_")
+	}
+	if collation == "" && charset != "" {
+		collation = v.DefaultCollation(charset)
+	}
+	return charset, collation
+}
+
 // TableOption describes a possible table option, such as `ENGINE=InnoDB`
 type TableOption struct {
 	Key        string
@@ -150,3 +227,18 @@ func NewTableOption(k, v string, q bool) *TableOption {
 }
 
 func (opt *TableOption) ID() string { return "tableopt#" + strings.ToLower(opt.Key) }
+
+// Canonical returns the canonical form of the table option.
+// The character sets and the collations are renamed to their canonical names,
+// e.g. utf8 is renamed to utf8mb3, as Database.Canonical does.
+// It is intended to compare options, and it is not always valid as DDL.
+func (opt *TableOption) Canonical() *TableOption {
+	ret := *opt
+	switch opt.ID() {
+	case "tableopt#default character set":
+		ret.Value = canonicalCharset(ret.Value)
+	case "tableopt#default collate":
+		ret.Value = canonicalCollation(ret.Value)
+	}
+	return &ret
+}
@@ -3,6 +3,8 @@ package model
 import (
 	"strconv"
 	"strings"
+
+	"github.com/shogo82148/schemalex-deploy/internal/util"
 )
 
 // NullState describes the possible NULL constraint of a column
@@ -17,10 +19,27 @@ const (
 	NullStateNotNull
 )
 
+// GeneratedStorage describes how the values of a generated column are stored.
+type GeneratedStorage int
+
+// List of possible GeneratedStorages. GeneratedStorageNone specifies that
+// the storage kind is omitted, and it is treated as VIRTUAL.
+// GeneratedStorageVirtual specifies that the values are evaluated when rows are read.
+// GeneratedStorageStored specifies that the values are evaluated and stored when rows are written.
+const (
+	GeneratedStorageNone GeneratedStorage = iota
+	GeneratedStorageVirtual
+	GeneratedStorageStored
+)
+
 type DefaultValue struct {
 	Valid  bool
 	Value  string
 	Quoted bool
+
+	// Expr is true if the default value is an expression, such as `DEFAULT (uuid())`.
+	// Value is the expression without the surrounding parentheses.
+	Expr bool
 }
 
 type Length struct {
@@ -59,6 +78,22 @@ type TableColumn struct {
 	Unsigned      bool
 	ZeroFill      bool
 	SRID          MaybeInteger
+
+	// Generated is the expression of a generated column,
+	// without the surrounding parentheses.
+	Generated        MaybeString
+	GeneratedStorage GeneratedStorage
+
+	// Invisible is true if the column is hidden from `SELECT *`.
+	Invisible bool
+
+	// Checks are the CHECK constraints in the column definition.
+	// Table.Normalize moves them into the table.
+	Checks []*Check
+
+	// RenamedFrom is the previous name of the column.
+	// It is set by the `-- schemalex:renamed-from old_name` annotation.
+	RenamedFrom MaybeIdent
 }
 
 // NewTableColumn creates a new TableColumn with the given name
@@ -135,6 +170,12 @@ func (t *TableColumn) Normalize() *TableColumn {
 		nullState = NullStateNone
 	}
 
+	generatedStorage := t.GeneratedStorage
+	if t.Generated.Valid && generatedStorage == GeneratedStorageNone {
+		// VIRTUAL is the default storage kind.
+		generatedStorage = GeneratedStorageVirtual
+	}
+
 	if t.Default.Valid {
 		switch t.Type {
 		case ColumnTypeTinyInt, ColumnTypeSmallInt,
@@ -158,7 +199,8 @@ func (t *TableColumn) Normalize() *TableColumn {
 				t.Default.Quoted = false
 			}
 		}
-	} else {
+	} else if !t.Generated.Valid {
+		// generated columns cannot have default values.
 		switch t.Type {
 		case ColumnTypeTinyText, ColumnTypeTinyBlob,
 			ColumnTypeBlob, ColumnTypeText,
@@ -181,6 +223,7 @@ func (t *TableColumn) Normalize() *TableColumn {
 	}
 
 	col.NullState = nullState
+	col.GeneratedStorage = generatedStorage
 
 	if removeQuotes {
 		col.Default.Valid = true
@@ -195,3 +238,99 @@ func (t *TableColumn) Normalize() *TableColumn {
 	}
 	return &col
 }
+
+// Canonical returns the canonical form of the normalized column on the server version.
+// The columns that have the same definition on the server have the same canonical form,
+// even if SHOW CREATE TABLE and hand-written DDL describe them differently.
+// It is intended to compare columns, and it is not always valid as DDL.
+// table is the table that the column belongs to, and it may be nil.
+func (t *TableColumn) Canonical(v ServerVersion, table *Table) *TableColumn {
+	col := *t
+
+	// the column inherits the character set and the collation of the table,
+	// so specifying the same ones as the table is same as specifying nothing.
+	if charset, collation := table.defaultCharset(v); charset != "" && (col.CharacterSet.Valid || col.Collation.Valid) {
+		var c, cl string
+		if col.CharacterSet.Valid {
+			c = canonicalCharset(string(col.CharacterSet.Ident))
+		} else {
+			c, _, _ = strings.Cut(canonicalCollation(string(col.Collation.Ident)), "_")
+		}
+		if col.Collation.Valid {
+			cl = canonicalCollation(string(col.Collation.Ident))
+		} else {
+			cl = v.DefaultCollation(c)
+		}
+		if c == charset && cl == collation {
+			col.CharacterSet = MaybeIdent{}
+			col.Collation = MaybeIdent{}
+		}
+	}
+
+	// utf8 is an alias for utf8mb3, and the default collation is same as no collation.
+	if col.CharacterSet.Valid {
+		col.CharacterSet.Ident = Ident(canonicalCharset(string(col.CharacterSet.Ident)))
+	}
+	if col.Collation.Valid {
+		col.Collation.Ident = Ident(canonicalCollation(string(col.Collation.Ident)))
+
+		// SHOW CREATE TABLE omits the character set inherited from the table,
+		// but the name of the collation starts with it.
+		charset := string(col.CharacterSet.Ident)
+		if !col.CharacterSet.Valid {
+			charset, _, _ = strings.Cut(string(col.Collation.Ident), "_")
+		}
+		if string(col.Collation.Ident) == v.DefaultCollation(charset) {
+			col.Collation = MaybeIdent{}
+		}
+	}
+
+	// MySQL 8.0.19 and later don't show the display widths of integer types.
+	if v.IgnoresDisplayWidth(&col) {
+		col.Length = nil
+	}
+
+	// DEFAULT NULL is implicit for nullable columns.
+	// Some servers show it, e.g. MariaDB shows it for TEXT columns, but the others don't.
+	if col.NullState != NullStateNotNull && col.Default.Valid && !col.Default.Quoted && strings.EqualFold(col.Default.Value, "NULL") {
+		col.Default = DefaultValue{}
+	}
+
+	// SHOW CREATE TABLE shows the expressions in lower case with quoted identifiers.
+	if col.Default.Valid && col.Default.Expr {
+		col.Default.Value = util.NormalizeSQL(col.Default.Value)
+	}
+
+	// CURRENT_TIMESTAMP has some synonyms, and MariaDB shows it as current_timestamp().
+	if col.Default.Valid && !col.Default.Quoted && !col.Default.Expr {
+		col.Default.Value = canonicalTimestamp(col.Default.Value)
+	}
+	if col.AutoUpdate.Valid {
+		col.AutoUpdate.Value = canonicalTimestamp(col.AutoUpdate.Value)
+	}
+	return &col
+}
+
+// canonicalTimestamp converts the synonyms of CURRENT_TIMESTAMP, such as NOW() and current_timestamp(),
+// into CURRENT_TIMESTAMP or CURRENT_TIMESTAMP(fsp).
+func canonicalTimestamp(s string) string {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for _, name := range []string{"CURRENT_TIMESTAMP", "NOW", "LOCALTIMESTAMP", "LOCALTIME"} {
+		rest, ok := strings.CutPrefix(upper, name)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		switch {
+		case rest == "" || rest == "()":
+			return "CURRENT_TIMESTAMP"
+		case strings.HasPrefix(rest, "(") && strings.HasSuffix(rest, ")"):
+			fsp := strings.TrimSpace(rest[1 : len(rest)-1])
+			if fsp == "0" {
+				return "CURRENT_TIMESTAMP"
+			}
+			return "CURRENT_TIMESTAMP(" + fsp + ")"
+		}
+	}
+	return s
+}
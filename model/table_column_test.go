@@ -210,3 +210,195 @@ func TestTableColumnNormalize(t *testing.T) {
 		})
 	}
 }
+
+func TestTableColumnCanonical(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version ServerVersion
+		table   *Table
+		a, b    *TableColumn
+		equal   bool
+	}{
+		{
+			name:    "display width of int on MySQL 8.0",
+			version: ServerVersionMySQL80,
+			a:       &TableColumn{Name: "foo", Type: ColumnTypeInt, Length: NewLength("11"), NullState: NullStateNotNull},
+			b:       &TableColumn{Name: "foo", Type: ColumnTypeInt, NullState: NullStateNotNull},
+			equal:   true,
+		},
+		{
+			name:    "display width of int on MySQL 5.7",
+			version: ServerVersionMySQL57,
+			a:       &TableColumn{Name: "foo", Type: ColumnTypeInt, Length: NewLength("11"), NullState: NullStateNotNull},
+			b:       &TableColumn{Name: "foo", Type: ColumnTypeInt, Length: NewLength("10"), NullState: NullStateNotNull},
+			equal:   false,
+		},
+		{
+			name:    "tinyint(1) keeps the display width",
+			version: ServerVersionMySQL84,
+			a:       &TableColumn{Name: "foo", Type: ColumnTypeTinyInt, Length: NewLength("1"), NullState: NullStateNotNull},
+			b:       &TableColumn{Name: "foo", Type: ColumnTypeTinyInt, Length: NewLength("4"), NullState: NullStateNotNull},
+			equal:   false,
+		},
+		{
+			name:    "utf8 is an alias for utf8mb3",
+			version: ServerVersionMySQL80,
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeVarChar, Length: NewLength("10"),
+				CharacterSet: MaybeIdent{Ident: "utf8", Valid: true},
+				Collation:    MaybeIdent{Ident: "utf8_bin", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeVarChar, Length: NewLength("10"),
+				CharacterSet: MaybeIdent{Ident: "utf8mb3", Valid: true},
+				Collation:    MaybeIdent{Ident: "utf8mb3_bin", Valid: true},
+			},
+			equal: true,
+		},
+		{
+			name:    "default collation of utf8mb4 on MySQL 8.0",
+			version: ServerVersionMySQL80,
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+				Collation:    MaybeIdent{Ident: "utf8mb4_0900_ai_ci", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+			},
+			equal: true,
+		},
+		{
+			name:    "character set of the table",
+			version: ServerVersionMySQL80,
+			table: &Table{
+				Options: []*TableOption{NewTableOption("DEFAULT CHARACTER SET", "utf8mb4", false)},
+			},
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+			},
+			equal: true,
+		},
+		{
+			name:    "collation of the table",
+			version: ServerVersionMySQL80,
+			table: &Table{
+				Options: []*TableOption{
+					NewTableOption("DEFAULT CHARACTER SET", "utf8mb4", false),
+					NewTableOption("DEFAULT COLLATE", "utf8mb4_bin", false),
+				},
+			},
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+				Collation:    MaybeIdent{Ident: "utf8mb4_bin", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+			},
+			equal: true,
+		},
+		{
+			name:    "default collation differs from the collation of the table",
+			version: ServerVersionMySQL80,
+			table: &Table{
+				Options: []*TableOption{NewTableOption("DEFAULT COLLATE", "utf8mb4_bin", false)},
+			},
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+			},
+			equal: false,
+		},
+		{
+			name:    "default collation of utf8mb4 on MariaDB",
+			version: ServerVersionMariaDB,
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+				Collation:    MaybeIdent{Ident: "utf8mb4_0900_ai_ci", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				CharacterSet: MaybeIdent{Ident: "utf8mb4", Valid: true},
+			},
+			equal: false,
+		},
+		{
+			name:    "collation of the character set inherited from the table",
+			version: ServerVersionMySQL57,
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeText,
+				Collation: MaybeIdent{Ident: "utf8mb4_general_ci", Valid: true},
+			},
+			b:     &TableColumn{Name: "foo", Type: ColumnTypeText},
+			equal: true,
+		},
+		{
+			name:    "implicit DEFAULT NULL",
+			version: ServerVersionMariaDB,
+			a:       &TableColumn{Name: "foo", Type: ColumnTypeText, Default: DefaultValue{Value: "NULL", Valid: true}},
+			b:       &TableColumn{Name: "foo", Type: ColumnTypeText},
+			equal:   true,
+		},
+		{
+			name:    "current_timestamp() of MariaDB",
+			version: ServerVersionMariaDB,
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeDateTime, NullState: NullStateNotNull,
+				Default:    DefaultValue{Value: "current_timestamp()", Valid: true},
+				AutoUpdate: MaybeString{Value: "current_timestamp()", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeDateTime, NullState: NullStateNotNull,
+				Default:    DefaultValue{Value: "CURRENT_TIMESTAMP", Valid: true},
+				AutoUpdate: MaybeString{Value: "NOW()", Valid: true},
+			},
+			equal: true,
+		},
+		{
+			name:    "fractional seconds precision",
+			version: ServerVersionMySQL80,
+			a: &TableColumn{
+				Name: "foo", Type: ColumnTypeDateTime, Length: NewLength("6"), NullState: NullStateNotNull,
+				Default: DefaultValue{Value: "CURRENT_TIMESTAMP(6)", Valid: true},
+			},
+			b: &TableColumn{
+				Name: "foo", Type: ColumnTypeDateTime, Length: NewLength("6"), NullState: NullStateNotNull,
+				Default: DefaultValue{Value: "CURRENT_TIMESTAMP", Valid: true},
+			},
+			equal: false,
+		},
+		{
+			name:    "expression default",
+			version: ServerVersionMySQL80,
+			a:       &TableColumn{Name: "foo", Type: ColumnTypeBinary, Default: DefaultValue{Value: "uuid_to_bin(uuid())", Valid: true, Expr: true}},
+			b:       &TableColumn{Name: "foo", Type: ColumnTypeBinary, Default: DefaultValue{Value: "UUID_TO_BIN( UUID() )", Valid: true, Expr: true}},
+			equal:   true,
+		},
+		{
+			name:    "expression default is not a literal",
+			version: ServerVersionMySQL80,
+			a:       &TableColumn{Name: "foo", Type: ColumnTypeDateTime, Default: DefaultValue{Value: "now()", Valid: true, Expr: true}},
+			b:       &TableColumn{Name: "foo", Type: ColumnTypeDateTime, Default: DefaultValue{Value: "NOW()", Valid: true}},
+			equal:   false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := tc.a.Normalize().Canonical(tc.version, tc.table)
+			b := tc.b.Normalize().Canonical(tc.version, tc.table)
+			if diff := cmp.Diff(a, b); (diff == "") != tc.equal {
+				t.Errorf("want equal = %t, got (-a/+b)\n%s", tc.equal, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import "strings"
+
+// Trigger describes a trigger definition
+type Trigger struct {
+	Name        Ident
+	IfNotExists bool
+	Definer     string // e.g. `root`@`localhost` or CURRENT_USER
+	Timing      string // BEFORE or AFTER
+	Event       string // INSERT, UPDATE or DELETE
+	Table       Ident
+
+	// Order is the order of the trigger, FOLLOWS or PRECEDES.
+	// OrderTrigger is the name of the other trigger.
+	Order        string
+	OrderTrigger Ident
+
+	Body string // the trigger body, such as `SET NEW.a = 1` and `BEGIN ... END`
+}
+
+// NewTrigger creates a new trigger with the given name
+func NewTrigger(name Ident) *Trigger {
+	return &Trigger{
+		Name: name,
+	}
+}
+
+func (t *Trigger) ID() string {
+	return "trigger#" + strings.ToLower(string(t.Name))
+}
@@ -0,0 +1,26 @@
+package model
+
+import "strings"
+
+// View describes a view definition
+type View struct {
+	Name        Ident
+	OrReplace   bool
+	Algorithm   string // UNDEFINED, MERGE or TEMPTABLE
+	Definer     string // e.g. `root`@`localhost` or CURRENT_USER
+	SQLSecurity string // DEFINER or INVOKER
+	Columns     []Ident
+	Definition  string // the select statement
+	CheckOption string // CASCADED or LOCAL
+}
+
+// NewView creates a new view with the given name
+func NewView(name Ident) *View {
+	return &View{
+		Name: name,
+	}
+}
+
+func (v *View) ID() string {
+	return "view#" + strings.ToLower(string(v.Name))
+}
@@ -3,10 +3,12 @@ package schemalex
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
 	myerrors "github.com/shogo82148/schemalex-deploy/internal/errors"
+	"github.com/shogo82148/schemalex-deploy/internal/util"
 	"github.com/shogo82148/schemalex-deploy/model"
 )
 
@@ -30,11 +32,14 @@ const (
 
 	coloptEverythingElse
 
+	coloptGenerated     = coloptEverythingElse
 	coloptNull          = coloptEverythingElse
 	coloptDefault       = coloptEverythingElse
 	coloptAutoIncrement = coloptEverythingElse
 	coloptKey           = coloptEverythingElse
+	coloptCheck         = coloptEverythingElse
 	coloptComment       = coloptEverythingElse
+	coloptVisibility    = coloptEverythingElse
 )
 
 const (
@@ -57,9 +62,17 @@ func New() *Parser {
 }
 
 type parseCtx struct {
+	file   string
 	input  []byte
 	lexsrc []*Token
 	idx    int
+
+	// renamedFrom is the renamed-from annotation found in the skipped comments.
+	renamedFrom model.MaybeIdent
+
+	// database is the current database selected by the USE statement.
+	// the tables without the database name belong to it.
+	database model.Ident
 }
 
 func newParseCtx() *parseCtx {
@@ -106,11 +119,52 @@ func (p *Parser) Parse(src []byte) (model.Stmts, error) {
 	ctx := newParseCtx()
 	ctx.input = src
 	ctx.lexsrc = lex(src)
+	return p.parse(ctx)
+}
+
+// ParseFile parses the SQL statements in the file.
+// The ParseError returned reports the file name.
+func (p *Parser) ParseFile(name string) (model.Stmts, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newParseCtx()
+	ctx.file = name
+	ctx.input = src
+	ctx.lexsrc = lex(src)
+	return p.parse(ctx)
+}
+
+// ParseFiles parses the SQL statements in the files, and concatenates them.
+// The names may be directories or glob patterns; they are expanded by ExpandFiles.
+func (p *Parser) ParseFiles(names ...string) (model.Stmts, error) {
+	files, err := ExpandFiles(names...)
+	if err != nil {
+		return nil, err
+	}
 
 	var stmts model.Stmts
+	for _, file := range files {
+		s, err := p.ParseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s...)
+	}
+	return stmts, nil
+}
+
+func (p *Parser) parse(ctx *parseCtx) (model.Stmts, error) {
+	var stmts model.Stmts
 LOOP:
 	for {
+		// the annotations in the comments before the statement are applied to the statement.
+		ctx.renamedFrom = model.MaybeIdent{}
 		ctx.skipWhiteSpaces()
+		renamedFrom := ctx.takeRenamedFrom()
+
 		switch t := ctx.peek(); t.Type {
 		case CREATE:
 			stmt, err := p.parseCreate(ctx)
@@ -124,15 +178,25 @@ LOOP:
 				}
 				return nil, fmt.Errorf("failed to parse create: %w", err)
 			}
+			if table, ok := stmt.(*model.Table); ok {
+				table.RenamedFrom = renamedFrom
+				setTableSchema(table, ctx.database)
+			}
 			stmts = append(stmts, stmt)
 		case COMMENT_IDENT:
 			ctx.advance()
-		case DROP, SET, USE:
+		case USE:
+			database, err := p.parseUse(ctx)
+			if err != nil {
+				return nil, err
+			}
+			ctx.database = database
+		case DROP, SET:
 			// We don't do anything about these
 		S1:
 			for {
 				switch t := ctx.peek(); t.Type {
-				case SEMICOLON:
+				case SEMICOLON, DELIMITER:
 					ctx.advance()
 					fallthrough
 				case EOF:
@@ -141,7 +205,7 @@ LOOP:
 					ctx.advance()
 				}
 			}
-		case SEMICOLON:
+		case SEMICOLON, DELIMITER:
 			// you could have statements where it's just empty, followed by a
 			// semicolon. These are just empty lines, so we just skip and go
 			// process the next statement
@@ -158,6 +222,72 @@ LOOP:
 	return stmts, nil
 }
 
+// https://dev.mysql.com/doc/refman/8.0/en/use.html
+func (p *Parser) parseUse(ctx *parseCtx) (model.Ident, error) {
+	if t := ctx.next(); t.Type != USE {
+		return "", errors.New(`expected USE`)
+	}
+
+	ctx.skipWhiteSpaces()
+	var database model.Ident
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		database = t.Ident()
+	default:
+		return "", newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+
+	ctx.skipWhiteSpaces()
+	switch t := ctx.next(); t.Type {
+	case SEMICOLON, DELIMITER, EOF:
+	default:
+		return "", newParseError(ctx, t, "expected SEMICOLON or EOF")
+	}
+	return database, nil
+}
+
+// setTableSchema sets the current database to the table without the database name.
+// The references to the tables in the same database are described without the database name,
+// like SHOW CREATE TABLE does.
+func setTableSchema(table *model.Table, database model.Ident) {
+	if table.Schema == "" && database != "" {
+		table.Schema = database
+		for _, idx := range table.Indexes {
+			idx.Table = table.ID()
+		}
+	}
+	for _, idx := range table.Indexes {
+		if idx.Reference == nil {
+			continue
+		}
+		if strings.EqualFold(string(idx.Reference.TableSchema), string(table.Schema)) {
+			idx.Reference.TableSchema = ""
+		}
+	}
+}
+
+// parseTableName parses the name of the table that may be qualified with the database name,
+// e.g. `db`.`table`.
+func (p *Parser) parseTableName(ctx *parseCtx) (schema, name model.Ident, err error) {
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		name = t.Ident()
+	default:
+		return "", "", newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+
+	if t := ctx.peek(); t.Type != DOT {
+		return "", name, nil
+	}
+	ctx.advance()
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		return name, t.Ident(), nil
+	default:
+		return "", "", newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+}
+
 func (p *Parser) parseCreate(ctx *parseCtx) (model.Stmt, error) {
 	if t := ctx.next(); t.Type != CREATE {
 		return nil, errors.New(`expected CREATE`)
@@ -165,22 +295,44 @@ func (p *Parser) parseCreate(ctx *parseCtx) (model.Stmt, error) {
 	ctx.skipWhiteSpaces()
 	switch t := ctx.peek(); t.Type {
 	case DATABASE:
-		if _, err := p.parseCreateDatabase(ctx); err != nil {
-			return nil, err
-		}
-		return nil, myerrors.Ignorable(nil)
+		return p.parseCreateDatabase(ctx)
 	case TABLE:
 		return p.parseCreateTable(ctx)
+	case OR:
+		return p.parseCreateView(ctx)
+	case IDENT:
+		switch {
+		case isKeyword(t, "DEFINER"):
+			switch t := p.peekAfterDefiner(ctx); {
+			case isKeyword(t, "PROCEDURE"), isKeyword(t, "FUNCTION"):
+				return p.parseCreateRoutine(ctx)
+			case isKeyword(t, "TRIGGER"):
+				return p.parseCreateTrigger(ctx)
+			case isKeyword(t, "EVENT"):
+				return p.parseCreateEvent(ctx)
+			}
+			return p.parseCreateView(ctx)
+		case isKeyword(t, "ALGORITHM"), isKeyword(t, "SQL"), isKeyword(t, "VIEW"):
+			return p.parseCreateView(ctx)
+		case isKeyword(t, "SCHEMA"):
+			return p.parseCreateDatabase(ctx)
+		case isKeyword(t, "PROCEDURE"), isKeyword(t, "FUNCTION"):
+			return p.parseCreateRoutine(ctx)
+		case isKeyword(t, "TRIGGER"):
+			return p.parseCreateTrigger(ctx)
+		case isKeyword(t, "EVENT"):
+			return p.parseCreateEvent(ctx)
+		}
+		return nil, newParseError(ctx, t, "expected DATABASE, TABLE, VIEW, PROCEDURE, FUNCTION, TRIGGER or EVENT")
 	default:
-		return nil, newParseError(ctx, t, "expected DATABASE or TABLE")
+		return nil, newParseError(ctx, t, "expected DATABASE, TABLE, VIEW, PROCEDURE, FUNCTION, TRIGGER or EVENT")
 	}
 }
 
-// https://dev.mysql.com/doc/refman/5.5/en/create-database.html
-// TODO: charset, collation
+// https://dev.mysql.com/doc/refman/8.0/en/create-database.html
 func (p *Parser) parseCreateDatabase(ctx *parseCtx) (*model.Database, error) {
-	if t := ctx.next(); t.Type != DATABASE {
-		return nil, errors.New(`expected DATABASE`)
+	if t := ctx.next(); t.Type != DATABASE && !isKeyword(t, "SCHEMA") {
+		return nil, errors.New(`expected DATABASE or SCHEMA`)
 	}
 
 	ctx.skipWhiteSpaces()
@@ -205,27 +357,255 @@ func (p *Parser) parseCreateDatabase(ctx *parseCtx) (*model.Database, error) {
 	}
 
 	database.IfNotExists = notexists
-	p.eol(ctx)
+	if err := p.parseCreateDatabaseOptions(ctx, database); err != nil {
+		return nil, err
+	}
 	return database, nil
 }
 
-// http://dev.mysql.com/doc/refman/5.6/en/create-table.html
-func (p *Parser) parseCreateTable(ctx *parseCtx) (*model.Table, error) {
-	if t := ctx.next(); t.Type != TABLE {
-		return nil, errors.New(`expected TABLE`)
+// parseCreateDatabaseOptions parses the options of CREATE DATABASE, such as `DEFAULT CHARACTER SET = utf8mb4`.
+func (p *Parser) parseCreateDatabaseOptions(ctx *parseCtx, database *model.Database) error {
+	for {
+		ctx.skipWhiteSpaces()
+		t := ctx.next()
+		hasDefault := t.Type == DEFAULT
+		if hasDefault {
+			// DEFAULT is optional.
+			ctx.skipWhiteSpaces()
+			t = ctx.next()
+		}
+		switch {
+		case t.Type == CHARACTER, t.Type == CHARSET:
+			if t.Type == CHARACTER {
+				if _, err := p.parseIdents(ctx, SET); err != nil {
+					return err
+				}
+			}
+			opt, err := p.parseTableOptionValue(ctx, "DEFAULT CHARACTER SET", IDENT, BACKTICK_IDENT, BINARY)
+			if err != nil {
+				return err
+			}
+			database.CharacterSet = model.MaybeIdent{Ident: model.Ident(opt.Value), Valid: true}
+		case t.Type == COLLATE:
+			opt, err := p.parseTableOptionValue(ctx, "DEFAULT COLLATE", IDENT, BACKTICK_IDENT)
+			if err != nil {
+				return err
+			}
+			database.Collation = model.MaybeIdent{Ident: model.Ident(opt.Value), Valid: true}
+		case isKeyword(t, "ENCRYPTION"):
+			opt, err := p.parseTableOptionValue(ctx, "ENCRYPTION", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT)
+			if err != nil {
+				return err
+			}
+			database.Encryption = model.MaybeString{Value: opt.Value, Valid: true}
+		case (t.Type == SEMICOLON || t.Type == DELIMITER || t.Type == EOF) && !hasDefault:
+			ctx.rewind()
+			return nil
+		default:
+			return newParseError(ctx, t, "expected CHARACTER SET, COLLATE, ENCRYPTION, SEMICOLON or EOF")
+		}
 	}
+}
 
-	var table *model.Table
+// https://dev.mysql.com/doc/refman/8.0/en/create-view.html
+// Start parsing after `CREATE`
+func (p *Parser) parseCreateView(ctx *parseCtx) (*model.View, error) {
+	var orReplace bool
+	var algorithm, definer, security string
+
+	// OR REPLACE
+	if ctx.peek().Type == OR {
+		ctx.advance()
+		if _, err := p.parseIdents(ctx, REPLACE); err != nil {
+			return nil, err
+		}
+		ctx.skipWhiteSpaces()
+		orReplace = true
+	}
+
+	// ALGORITHM = {UNDEFINED | MERGE | TEMPTABLE}
+	if isKeyword(ctx.peek(), "ALGORITHM") {
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+		if t := ctx.next(); t.Type != EQUAL {
+			return nil, newParseError(ctx, t, "expected EQUAL")
+		}
+		ctx.skipWhiteSpaces()
+		t := ctx.next()
+		v := strings.ToUpper(t.Value)
+		if t.Type != IDENT || (v != "UNDEFINED" && v != "MERGE" && v != "TEMPTABLE") {
+			return nil, newParseError(ctx, t, "expected UNDEFINED, MERGE or TEMPTABLE")
+		}
+		algorithm = v
+		ctx.skipWhiteSpaces()
+	}
+
+	// DEFINER = user
+	if isKeyword(ctx.peek(), "DEFINER") {
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+		if t := ctx.next(); t.Type != EQUAL {
+			return nil, newParseError(ctx, t, "expected EQUAL")
+		}
+		ctx.skipWhiteSpaces()
+		v, err := p.parseUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		definer = v
+		ctx.skipWhiteSpaces()
+	}
+
+	// SQL SECURITY { DEFINER | INVOKER }
+	if isKeyword(ctx.peek(), "SQL") {
+		ctx.advance()
+		if err := p.parseKeywords(ctx, "SECURITY"); err != nil {
+			return nil, err
+		}
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); {
+		case isKeyword(t, "DEFINER"):
+			security = "DEFINER"
+		case t.Type == IDENT && strings.EqualFold(t.Value, "INVOKER"):
+			security = "INVOKER"
+		default:
+			return nil, newParseError(ctx, t, "expected DEFINER or INVOKER")
+		}
+		ctx.skipWhiteSpaces()
+	}
 
+	if t := ctx.next(); !isKeyword(t, "VIEW") {
+		return nil, newParseError(ctx, t, "expected VIEW")
+	}
 	ctx.skipWhiteSpaces()
-	var temporary bool
-	if t := ctx.peek(); t.Type == TEMPORARY {
+
+	var view *model.View
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		view = model.NewView(t.Ident())
+	default:
+		return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+	view.OrReplace = orReplace
+	view.Algorithm = algorithm
+	view.Definer = definer
+	view.SQLSecurity = security
+
+	// column list
+	ctx.skipWhiteSpaces()
+	if ctx.peek().Type == LPAREN {
 		ctx.advance()
+	COLUMNS:
+		for {
+			ctx.skipWhiteSpaces()
+			switch t := ctx.next(); t.Type {
+			case IDENT, BACKTICK_IDENT:
+				view.Columns = append(view.Columns, t.Ident())
+			default:
+				return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+			}
+			ctx.skipWhiteSpaces()
+			switch t := ctx.next(); t.Type {
+			case COMMA:
+			case RPAREN:
+				break COLUMNS
+			default:
+				return nil, newParseError(ctx, t, "expected COMMA or RPAREN")
+			}
+		}
 		ctx.skipWhiteSpaces()
-		temporary = true
 	}
 
-	// IF NOT EXISTS
+	if t := ctx.next(); t.Type != AS {
+		return nil, newParseError(ctx, t, "expected AS")
+	}
+	ctx.skipWhiteSpaces()
+
+	// the select statement continues until the end of the statement.
+	begin := ctx.peek()
+	var tokens []*Token
+	for {
+		t := ctx.peek()
+		if t.Type == SEMICOLON || t.Type == DELIMITER || t.Type == EOF {
+			break
+		}
+		if t.Type != SPACE && t.Type != COMMENT_IDENT {
+			tokens = append(tokens, t)
+		}
+		ctx.advance()
+	}
+	end := ctx.peek().Pos
+
+	// WITH [CASCADED | LOCAL] CHECK OPTION
+	if n := len(tokens); n >= 3 && tokens[n-2].Type == CHECK && tokens[n-1].Type == IDENT && strings.EqualFold(tokens[n-1].Value, "OPTION") {
+		switch {
+		case tokens[n-3].Type == WITH:
+			view.CheckOption = "CASCADED"
+			end = tokens[n-3].Pos
+		case n >= 4 && tokens[n-4].Type == WITH && tokens[n-3].Type == IDENT &&
+			(strings.EqualFold(tokens[n-3].Value, "CASCADED") || strings.EqualFold(tokens[n-3].Value, "LOCAL")):
+			view.CheckOption = strings.ToUpper(tokens[n-3].Value)
+			end = tokens[n-4].Pos
+		}
+	}
+
+	view.Definition = strings.TrimSpace(string(ctx.input[begin.Pos:end]))
+	if view.Definition == "" {
+		return nil, newParseError(ctx, begin, "expected select statement")
+	}
+	return view, nil
+}
+
+// peekAfterDefiner returns the token after `DEFINER = user`, such as VIEW and PROCEDURE.
+// It reads nothing.
+func (p *Parser) peekAfterDefiner(ctx *parseCtx) *Token {
+	idx := ctx.idx
+	defer func() { ctx.idx = idx }()
+
+	if _, err := p.parseDefiner(ctx); err != nil {
+		return eofToken
+	}
+	return ctx.peek()
+}
+
+// parseDefiner parses `DEFINER = user` if exists.
+func (p *Parser) parseDefiner(ctx *parseCtx) (string, error) {
+	if !isKeyword(ctx.peek(), "DEFINER") {
+		return "", nil
+	}
+	ctx.advance()
+	ctx.skipWhiteSpaces()
+	if t := ctx.next(); t.Type != EQUAL {
+		return "", newParseError(ctx, t, "expected EQUAL")
+	}
+	ctx.skipWhiteSpaces()
+	v, err := p.parseUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	ctx.skipWhiteSpaces()
+	return v, nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/create-procedure.html
+// Start parsing after `CREATE`
+func (p *Parser) parseCreateRoutine(ctx *parseCtx) (*model.Routine, error) {
+	definer, err := p.parseDefiner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var kind model.RoutineKind
+	switch t := ctx.next(); {
+	case isKeyword(t, "PROCEDURE"):
+		kind = model.RoutineKindProcedure
+	case isKeyword(t, "FUNCTION"):
+		kind = model.RoutineKindFunction
+	default:
+		return nil, newParseError(ctx, t, "expected PROCEDURE or FUNCTION")
+	}
+	ctx.skipWhiteSpaces()
+
 	var notexists bool
 	if ctx.peek().Type == IF {
 		ctx.advance()
@@ -236,197 +616,830 @@ func (p *Parser) parseCreateTable(ctx *parseCtx) (*model.Table, error) {
 		notexists = true
 	}
 
+	var routine *model.Routine
 	switch t := ctx.next(); t.Type {
 	case IDENT, BACKTICK_IDENT:
-		table = model.NewTable(t.Ident())
+		routine = model.NewRoutine(kind, t.Ident())
 	default:
 		return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
 	}
-	table.Temporary = temporary
-	table.IfNotExists = notexists
+	routine.IfNotExists = notexists
+	routine.Definer = definer
 
+	// the parameters may be empty.
 	ctx.skipWhiteSpaces()
-	switch t := ctx.peek(); t.Type {
-	case LIKE:
-		// CREATE TABLE foo LIKE bar
+	if t := ctx.peek(); t.Type != LPAREN {
+		return nil, newParseError(ctx, t, "expected LPAREN")
+	}
+	idx := ctx.idx
+	ctx.advance()
+	ctx.skipWhiteSpaces()
+	if ctx.peek().Type == RPAREN {
 		ctx.advance()
-		ctx.skipWhiteSpaces()
-		switch t := ctx.next(); t.Type {
-		case IDENT, BACKTICK_IDENT:
-			table.LikeTable.Valid = true
-			table.LikeTable.Ident = t.Ident()
-		default:
-			return nil, newParseError(ctx, t, "expected table name after LIKE")
+	} else {
+		ctx.idx = idx
+		params, err := p.parseParenthesizedExpr(ctx)
+		if err != nil {
+			return nil, err
 		}
+		routine.Params = params
+	}
 
+	// RETURNS type
+	if kind == model.RoutineKindFunction {
 		ctx.skipWhiteSpaces()
-		switch t := ctx.peek(); t.Type {
-		case EOF, SEMICOLON:
-			ctx.advance()
+		if t := ctx.next(); !isKeyword(t, "RETURNS") {
+			return nil, newParseError(ctx, t, "expected RETURNS")
 		}
-		return table, nil
-	case IF:
+		ctx.skipWhiteSpaces()
+		returns, err := p.parseRoutineReturns(ctx)
+		if err != nil {
+			return nil, err
+		}
+		routine.Returns = returns
+	}
+
+	if err := p.parseRoutineCharacteristics(ctx, routine); err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseRoutineBody(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routine.Body = body
+	return routine, nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/create-trigger.html
+// Start parsing after `CREATE`
+func (p *Parser) parseCreateTrigger(ctx *parseCtx) (*model.Trigger, error) {
+	definer, err := p.parseDefiner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := ctx.next(); !isKeyword(t, "TRIGGER") {
+		return nil, newParseError(ctx, t, "expected TRIGGER")
+	}
+	ctx.skipWhiteSpaces()
+
+	var notexists bool
+	if ctx.peek().Type == IF {
 		ctx.advance()
 		if _, err := p.parseIdents(ctx, NOT, EXISTS); err != nil {
-			return nil, newParseError(ctx, t, "should NOT EXISTS")
+			return nil, err
 		}
 		ctx.skipWhiteSpaces()
-		table.IfNotExists = true
+		notexists = true
 	}
 
-	if t := ctx.next(); t.Type != LPAREN {
-		return nil, newParseError(ctx, t, "expected LPAREN")
+	var trigger *model.Trigger
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		trigger = model.NewTrigger(t.Ident())
+	default:
+		return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
 	}
+	trigger.IfNotExists = notexists
+	trigger.Definer = definer
 
-	if err := p.parseCreateTableFields(ctx, table); err != nil {
-		return nil, err
+	// { BEFORE | AFTER }
+	ctx.skipWhiteSpaces()
+	switch t := ctx.next(); {
+	case isKeyword(t, "BEFORE"), isKeyword(t, "AFTER"):
+		trigger.Timing = strings.ToUpper(t.Value)
+	default:
+		return nil, newParseError(ctx, t, "expected BEFORE or AFTER")
 	}
 
-	table = table.Normalize()
-	return table, nil
-}
+	// { INSERT | UPDATE | DELETE }
+	ctx.skipWhiteSpaces()
+	switch t := ctx.next(); {
+	case isKeyword(t, "INSERT"), t.Type == UPDATE, t.Type == DELETE:
+		trigger.Event = strings.ToUpper(t.Value)
+	default:
+		return nil, newParseError(ctx, t, "expected INSERT, UPDATE or DELETE")
+	}
+
+	// ON tbl_name
+	if _, err := p.parseIdents(ctx, ON); err != nil {
+		return nil, err
+	}
+	ctx.skipWhiteSpaces()
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		trigger.Table = t.Ident()
+	default:
+		return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+
+	// FOR EACH ROW
+	for _, word := range []string{"FOR", "EACH", "ROW"} {
+		ctx.skipWhiteSpaces()
+		if t := ctx.next(); !isKeyword(t, word) {
+			return nil, newParseError(ctx, t, "expected %s", word)
+		}
+	}
+
+	// { FOLLOWS | PRECEDES } other_trigger_name
+	ctx.skipWhiteSpaces()
+	if t := ctx.peek(); isKeyword(t, "FOLLOWS") || isKeyword(t, "PRECEDES") {
+		ctx.advance()
+		trigger.Order = strings.ToUpper(t.Value)
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case IDENT, BACKTICK_IDENT:
+			trigger.OrderTrigger = t.Ident()
+		default:
+			return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+		}
+		ctx.skipWhiteSpaces()
+	}
+
+	body, err := p.parseRoutineBody(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trigger.Body = body
+	return trigger, nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/create-event.html
+// Start parsing after `CREATE`
+func (p *Parser) parseCreateEvent(ctx *parseCtx) (*model.Event, error) {
+	definer, err := p.parseDefiner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := ctx.next(); !isKeyword(t, "EVENT") {
+		return nil, newParseError(ctx, t, "expected EVENT")
+	}
+	ctx.skipWhiteSpaces()
+
+	var notexists bool
+	if ctx.peek().Type == IF {
+		ctx.advance()
+		if _, err := p.parseIdents(ctx, NOT, EXISTS); err != nil {
+			return nil, err
+		}
+		ctx.skipWhiteSpaces()
+		notexists = true
+	}
+
+	var event *model.Event
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		event = model.NewEvent(t.Ident())
+	default:
+		return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+	event.IfNotExists = notexists
+	event.Definer = definer
+
+	// ON SCHEDULE schedule
+	if _, err := p.parseIdents(ctx, ON); err != nil {
+		return nil, err
+	}
+	ctx.skipWhiteSpaces()
+	if t := ctx.next(); !isKeyword(t, "SCHEDULE") {
+		return nil, newParseError(ctx, t, "expected SCHEDULE")
+	}
+	ctx.skipWhiteSpaces()
+	schedule, err := p.parseEventSchedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	event.Schedule = schedule
 
-// Start parsing after `CREATE TABLE *** (`
-func (p *Parser) parseCreateTableFields(ctx *parseCtx, stmt *model.Table) error {
 	for {
 		ctx.skipWhiteSpaces()
-		switch t := ctx.peek(); t.Type {
-		case CONSTRAINT:
-			if err := p.parseTableConstraint(ctx, stmt); err != nil {
-				return err
-			}
-		case PRIMARY:
-			if err := p.parseTablePrimaryKey(ctx, stmt); err != nil {
-				return err
+		switch t := ctx.peek(); {
+		case t.Type == ON:
+			// ON COMPLETION [NOT] PRESERVE
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			if t := ctx.next(); !isKeyword(t, "COMPLETION") {
+				return nil, newParseError(ctx, t, "expected COMPLETION")
 			}
-		case UNIQUE:
-			if err := p.parseTableUniqueKey(ctx, stmt); err != nil {
-				return err
+			ctx.skipWhiteSpaces()
+			event.Preserve = true
+			if ctx.peek().Type == NOT {
+				ctx.advance()
+				ctx.skipWhiteSpaces()
+				event.Preserve = false
 			}
-		case INDEX, KEY:
-			// TODO. separate to KEY and INDEX
-			if err := p.parseTableIndex(ctx, stmt); err != nil {
-				return err
+			if t := ctx.next(); !isKeyword(t, "PRESERVE") {
+				return nil, newParseError(ctx, t, "expected PRESERVE")
 			}
-		case FULLTEXT:
-			if err := p.parseTableFulltextIndex(ctx, stmt); err != nil {
-				return err
+		case isKeyword(t, "ENABLE"):
+			ctx.advance()
+			event.Status = "ENABLE"
+		case isKeyword(t, "DISABLE"):
+			// DISABLE [ON {REPLICA | SLAVE}]
+			ctx.advance()
+			event.Status = "DISABLE"
+			idx := ctx.idx
+			ctx.skipWhiteSpaces()
+			if ctx.peek().Type != ON {
+				ctx.idx = idx
+				continue
 			}
-		case SPATIAL:
-			if err := p.parseTableSpatialIndex(ctx, stmt); err != nil {
-				return err
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			switch t := ctx.next(); {
+			case isKeyword(t, "REPLICA"), isKeyword(t, "SLAVE"):
+				// SLAVE is the old name of REPLICA.
+				event.Status = "DISABLE ON REPLICA"
+			case isKeyword(t, "COMPLETION"):
+				// it is ON COMPLETION.
+				ctx.idx = idx
+			default:
+				return nil, newParseError(ctx, t, "expected REPLICA or SLAVE")
 			}
-		case FOREIGN:
-			if err := p.parseTableForeignKey(ctx, stmt); err != nil {
-				return err
+		case t.Type == COMMENT:
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			switch v := ctx.next(); v.Type {
+			case SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT:
+				event.Comment = model.MaybeString{Valid: true, Value: v.Value}
+			default:
+				return nil, newParseError(ctx, v, "expected SINGLE_QUOTE_IDENT or DOUBLE_QUOTE_IDENT")
 			}
-		case CHECK: // TODO
-			return newParseError(ctx, t, "unsupported field: CHECK")
-		case IDENT, BACKTICK_IDENT:
-			if err := p.parseTableColumn(ctx, stmt); err != nil {
-				return err
+		case isKeyword(t, "DO"):
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			body, err := p.parseRoutineBody(ctx)
+			if err != nil {
+				return nil, err
 			}
+			event.Body = body
+			return event, nil
 		default:
-			return newParseError(ctx, t, "unexpected create table field token: %s", t.Type)
+			return nil, newParseError(ctx, t, "expected ON COMPLETION, ENABLE, DISABLE, COMMENT or DO")
+		}
+	}
+}
+
+// parseEventSchedule parses the schedule of the event, which continues until the next clause.
+func (p *Parser) parseEventSchedule(ctx *parseCtx) (string, error) {
+	begin := ctx.peek()
+	end := begin.Pos
+	depth := 0
+LOOP:
+	for {
+		t := ctx.peek()
+		switch {
+		case t.Type == EOF, t.Type == SEMICOLON, t.Type == DELIMITER:
+			break LOOP
+		case t.Type == LPAREN:
+			depth++
+		case t.Type == RPAREN:
+			depth--
+		case depth > 0, t.Type == SPACE, t.Type == COMMENT_IDENT:
+		case t.Type == ON, t.Type == COMMENT, isKeyword(t, "ENABLE"), isKeyword(t, "DISABLE"), isKeyword(t, "DO"):
+			break LOOP
+		}
+		ctx.advance()
+		if t.Type != SPACE && t.Type != COMMENT_IDENT {
+			end = ctx.peek().Pos
+		}
+	}
+
+	schedule := strings.TrimSpace(string(ctx.input[begin.Pos:end]))
+	if schedule == "" {
+		return "", newParseError(ctx, begin, "expected schedule")
+	}
+	return schedule, nil
+}
+
+// parseRoutineReturns parses the return type of the function, such as `VARCHAR(10) CHARSET utf8mb4`.
+func (p *Parser) parseRoutineReturns(ctx *parseCtx) (string, error) {
+	begin := ctx.next()
+	if begin.Type != IDENT {
+		if _, ok := keywordIdentMap[strings.ToUpper(begin.Value)]; !ok {
+			return "", newParseError(ctx, begin, "expected data type")
 		}
+	}
+	end := ctx.peek().Pos
 
+	for {
+		idx := ctx.idx
 		ctx.skipWhiteSpaces()
-		switch t := ctx.peek(); t.Type {
-		case RPAREN:
+		t := ctx.peek()
+		switch {
+		case t.Type == LPAREN:
+			if _, err := p.parseParenthesizedExpr(ctx); err != nil {
+				return "", err
+			}
+			end = ctx.peek().Pos
+		case t.Type == UNSIGNED, t.Type == ZEROFILL, t.Type == BINARY,
+			isKeyword(t, "SIGNED"), isKeyword(t, "PRECISION"), isKeyword(t, "VARYING"):
 			ctx.advance()
-			if err := p.parseCreateTableOptions(ctx, stmt); err != nil {
-				return err
+			end = ctx.peek().Pos
+		case t.Type == CHARSET, t.Type == CHARACTER, t.Type == COLLATE:
+			ctx.advance()
+			if t.Type == CHARACTER {
+				if _, err := p.parseIdents(ctx, SET); err != nil {
+					return "", err
+				}
 			}
-			// partition option
-			if !p.eol(ctx) {
-				return newParseError(ctx, t, "expected EOL")
+			ctx.skipWhiteSpaces()
+			switch v := ctx.next(); v.Type {
+			case IDENT, BINARY, BACKTICK_IDENT, SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT:
+				end = ctx.peek().Pos
+			default:
+				return "", newParseError(ctx, v, "expected IDENT, BACKTICK_IDENT, SINGLE_QUOTE_IDENT or DOUBLE_QUOTE_IDENT")
 			}
-			return nil
-		case COMMA:
-			ctx.advance()
-			// Expecting another table field, keep looping
 		default:
-			return newParseError(ctx, t, "expected RPAREN or COMMA")
+			ctx.idx = idx
+			return string(ctx.input[begin.Pos:end]), nil
 		}
 	}
 }
 
-func (p *Parser) parseTableConstraint(ctx *parseCtx, table *model.Table) error {
-	if t := ctx.next(); t.Type != CONSTRAINT {
-		return newParseError(ctx, t, "expected CONSTRAINT")
-	}
-	ctx.skipWhiteSpaces()
-
-	var sym string
-	switch t := ctx.peek(); t.Type {
-	case IDENT, BACKTICK_IDENT:
-		// TODO: should be smarter
-		// (lestrrat): I don't understand. How?
-		sym = t.Value
-		ctx.advance()
+// parseRoutineCharacteristics parses the characteristics of the stored routine.
+func (p *Parser) parseRoutineCharacteristics(ctx *parseCtx, routine *model.Routine) error {
+	for {
 		ctx.skipWhiteSpaces()
+		switch t := ctx.peek(); {
+		case t.Type == COMMENT:
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			switch v := ctx.next(); v.Type {
+			case SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT:
+				routine.Comment = model.MaybeString{Valid: true, Value: v.Value}
+			default:
+				return newParseError(ctx, v, "expected SINGLE_QUOTE_IDENT or DOUBLE_QUOTE_IDENT")
+			}
+		case isKeyword(t, "LANGUAGE"):
+			ctx.advance()
+			if err := p.parseKeywords(ctx, "SQL"); err != nil {
+				return err
+			}
+		case t.Type == NOT:
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			if v := ctx.next(); !isKeyword(v, "DETERMINISTIC") {
+				return newParseError(ctx, v, "expected DETERMINISTIC")
+			}
+			routine.Deterministic = false
+		case isKeyword(t, "DETERMINISTIC"):
+			ctx.advance()
+			routine.Deterministic = true
+		case isKeyword(t, "CONTAINS"):
+			ctx.advance()
+			if err := p.parseKeywords(ctx, "SQL"); err != nil {
+				return err
+			}
+			routine.DataAccess = "CONTAINS SQL"
+		case t.Type == NO:
+			ctx.advance()
+			if err := p.parseKeywords(ctx, "SQL"); err != nil {
+				return err
+			}
+			routine.DataAccess = "NO SQL"
+		case isKeyword(t, "READS"), isKeyword(t, "MODIFIES"):
+			ctx.advance()
+			if err := p.parseKeywords(ctx, "SQL"); err != nil {
+				return err
+			}
+			if _, err := p.parseIdents(ctx, DATA); err != nil {
+				return err
+			}
+			routine.DataAccess = strings.ToUpper(t.Value) + " SQL DATA"
+		case isKeyword(t, "SQL"):
+			ctx.advance()
+			if err := p.parseKeywords(ctx, "SECURITY"); err != nil {
+				return err
+			}
+			ctx.skipWhiteSpaces()
+			switch v := ctx.next(); {
+			case isKeyword(v, "DEFINER"):
+				routine.SQLSecurity = "DEFINER"
+			case isKeyword(v, "INVOKER"):
+				routine.SQLSecurity = "INVOKER"
+			default:
+				return newParseError(ctx, v, "expected DEFINER or INVOKER")
+			}
+		default:
+			return nil
+		}
 	}
+}
 
-	var index *model.Index
-	switch t := ctx.peek(); t.Type {
-	case PRIMARY:
-		index = model.NewIndex(model.IndexKindPrimaryKey, table.ID())
-		if err := p.parseColumnIndexPrimaryKey(ctx, index); err != nil {
-			return err
+// parseRoutineBody parses the body of the routine, the trigger and the event, which continues until the end of the statement.
+// The compound statements may contain semicolons, so the blocks, such as `BEGIN ... END` and `CASE ... END CASE`, are tracked.
+func (p *Parser) parseRoutineBody(ctx *parseCtx) (string, error) {
+	// the body that is terminated by the delimiter changed by the DELIMITER command continues until it.
+	// otherwise, the semicolons in the compound statements are distinguished by their nesting.
+	delimited := ctx.delimited()
+
+	begin := ctx.peek()
+	end := begin.Pos
+	depth := 0
+	start := true // whether the next token begins a statement in the body
+	for {
+		t := ctx.peek()
+		if t.Type == EOF || t.Type == DELIMITER || (t.Type == SEMICOLON && depth == 0 && !delimited) {
+			break
 		}
-	case UNIQUE:
-		index = model.NewIndex(model.IndexKindUnique, table.ID())
-		if err := p.parseColumnIndexUniqueKey(ctx, index); err != nil {
-			return err
+		ctx.advance()
+		if t.Type == SPACE || t.Type == COMMENT_IDENT {
+			// the spaces and the comments at the end, such as the end of the executable comment, are trimmed.
+			continue
 		}
-	case FOREIGN:
-		index = model.NewIndex(model.IndexKindForeignKey, table.ID())
-		if err := p.parseColumnIndexForeignKey(ctx, index); err != nil {
-			return err
+		end = ctx.peek().Pos
+		if delimited {
+			continue
 		}
-	default:
-		return newParseError(ctx, t, "not supported")
-	}
 
-	if len(sym) > 0 {
-		index.ConstraintName = model.MaybeIdent{
-			Ident: model.Ident(sym),
-			Valid: true,
+		atStart := start
+		start = false
+		switch {
+		case t.Type == SEMICOLON, isKeyword(t, "THEN"), isKeyword(t, "ELSE"), isKeyword(t, "DO"):
+			start = true
+		case t.Type == ILLEGAL && t.Value == ":":
+			// the label of the block, such as `label: LOOP`.
+			start = true
+		case isKeyword(t, "BEGIN"), isKeyword(t, "LOOP"), isKeyword(t, "REPEAT") && atStart:
+			depth++
+			start = true
+		case isKeyword(t, "CASE"), isKeyword(t, "WHILE"), t.Type == IF && atStart:
+			// IF and REPEAT are also the names of the functions,
+			// so they begin the blocks only at the beginning of the statements.
+			depth++
+		case isKeyword(t, "END"):
+			idx := ctx.idx
+			ctx.skipWhiteSpaces()
+			switch next := ctx.peek(); {
+			case next.Type == IF, isKeyword(next, "CASE"), isKeyword(next, "LOOP"), isKeyword(next, "WHILE"), isKeyword(next, "REPEAT"):
+				// END IF, END CASE, END LOOP, END WHILE and END REPEAT close the blocks.
+				ctx.advance()
+				end = ctx.peek().Pos
+			default:
+				ctx.idx = idx
+			}
+			depth--
+			if depth < 0 {
+				depth = 0
+			}
 		}
 	}
 
-	table.Indexes = append(table.Indexes, index)
-	return nil
-}
-
-func (p *Parser) parseTablePrimaryKey(ctx *parseCtx, table *model.Table) error {
-	index := model.NewIndex(model.IndexKindPrimaryKey, table.ID())
-	if err := p.parseColumnIndexPrimaryKey(ctx, index); err != nil {
-		return err
+	body := strings.TrimSpace(string(ctx.input[begin.Pos:end]))
+	if body == "" {
+		return "", newParseError(ctx, begin, "expected routine body")
 	}
-	table.Indexes = append(table.Indexes, index)
-	return nil
+	return body, nil
 }
 
-func (p *Parser) parseTableUniqueKey(ctx *parseCtx, table *model.Table) error {
-	index := model.NewIndex(model.IndexKindUnique, table.ID())
-	if err := p.parseColumnIndexUniqueKey(ctx, index); err != nil {
-		return err
+// delimited returns whether the statement at the current position is terminated by
+// the delimiter changed by the DELIMITER command.
+func (pctx *parseCtx) delimited() bool {
+	if pctx.idx >= len(pctx.lexsrc) {
+		return false
 	}
-	table.Indexes = append(table.Indexes, index)
-	return nil
+	for _, t := range pctx.lexsrc[pctx.idx:] {
+		switch {
+		case t.Type == DELIMITER:
+			return true
+		case isDelimiterCommand(t):
+			// the delimiter is changed after the statement.
+			return false
+		}
+	}
+	return false
 }
 
-func (p *Parser) parseTableIndex(ctx *parseCtx, table *model.Table) error {
-	index := model.NewIndex(model.IndexKindNormal, table.ID())
-	if err := p.parseColumnIndexKey(ctx, index); err != nil {
-		return err
+// parseUser parses an account name, such as 'user'@'host' and CURRENT_USER.
+func (p *Parser) parseUser(ctx *parseCtx) (string, error) {
+	t := ctx.next()
+	switch t.Type {
+	case IDENT:
+		if strings.EqualFold(t.Value, "CURRENT_USER") {
+			// CURRENT_USER or CURRENT_USER()
+			if ctx.peek().Type == LPAREN {
+				ctx.advance()
+				if t := ctx.next(); t.Type != RPAREN {
+					return "", newParseError(ctx, t, "expected RPAREN")
+				}
+			}
+			return "CURRENT_USER", nil
+		}
+	case BACKTICK_IDENT, SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT:
+	default:
+		return "", newParseError(ctx, t, "expected user name")
 	}
-	table.Indexes = append(table.Indexes, index)
-	return nil
-}
+	user := util.Backquote(t.Value)
 
-func (p *Parser) parseTableFulltextIndex(ctx *parseCtx, table *model.Table) error {
+	if ctx.peek().Type != ATMARK {
+		return user, nil
+	}
+	ctx.advance()
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT, SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT:
+		return user + "@" + util.Backquote(t.Value), nil
+	default:
+		return "", newParseError(ctx, t, "expected host name")
+	}
+}
+
+// http://dev.mysql.com/doc/refman/5.6/en/create-table.html
+func (p *Parser) parseCreateTable(ctx *parseCtx) (*model.Table, error) {
+	if t := ctx.next(); t.Type != TABLE {
+		return nil, errors.New(`expected TABLE`)
+	}
+
+	var table *model.Table
+
+	ctx.skipWhiteSpaces()
+	var temporary bool
+	if t := ctx.peek(); t.Type == TEMPORARY {
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+		temporary = true
+	}
+
+	// IF NOT EXISTS
+	var notexists bool
+	if ctx.peek().Type == IF {
+		ctx.advance()
+		if _, err := p.parseIdents(ctx, NOT, EXISTS); err != nil {
+			return nil, err
+		}
+		ctx.skipWhiteSpaces()
+		notexists = true
+	}
+
+	schema, name, err := p.parseTableName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table = model.NewTable(name)
+	table.Schema = schema
+	table.Temporary = temporary
+	table.IfNotExists = notexists
+
+	ctx.skipWhiteSpaces()
+	switch t := ctx.peek(); t.Type {
+	case LIKE:
+		// CREATE TABLE foo LIKE bar
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case IDENT, BACKTICK_IDENT:
+			table.LikeTable.Valid = true
+			table.LikeTable.Ident = t.Ident()
+		default:
+			return nil, newParseError(ctx, t, "expected table name after LIKE")
+		}
+
+		ctx.skipWhiteSpaces()
+		switch t := ctx.peek(); t.Type {
+		case EOF, SEMICOLON, DELIMITER:
+			ctx.advance()
+		}
+		return table, nil
+	case IF:
+		ctx.advance()
+		if _, err := p.parseIdents(ctx, NOT, EXISTS); err != nil {
+			return nil, newParseError(ctx, t, "should NOT EXISTS")
+		}
+		ctx.skipWhiteSpaces()
+		table.IfNotExists = true
+	}
+
+	if t := ctx.next(); t.Type != LPAREN {
+		return nil, newParseError(ctx, t, "expected LPAREN")
+	}
+
+	if err := p.parseCreateTableFields(ctx, table); err != nil {
+		return nil, err
+	}
+
+	table = table.Normalize()
+	return table, nil
+}
+
+// Start parsing after `CREATE TABLE *** (`
+func (p *Parser) parseCreateTableFields(ctx *parseCtx, stmt *model.Table) error {
+	for {
+		// the annotations in the comments before the field are applied to the field.
+		ctx.renamedFrom = model.MaybeIdent{}
+		ctx.skipWhiteSpaces()
+		renamedFrom := ctx.takeRenamedFrom()
+
+		switch t := ctx.peek(); t.Type {
+		case CONSTRAINT:
+			if err := p.parseTableConstraint(ctx, stmt); err != nil {
+				return err
+			}
+		case PRIMARY:
+			if err := p.parseTablePrimaryKey(ctx, stmt); err != nil {
+				return err
+			}
+		case UNIQUE:
+			if err := p.parseTableUniqueKey(ctx, stmt); err != nil {
+				return err
+			}
+		case INDEX, KEY:
+			// TODO. separate to KEY and INDEX
+			if err := p.parseTableIndex(ctx, stmt); err != nil {
+				return err
+			}
+		case FULLTEXT:
+			if err := p.parseTableFulltextIndex(ctx, stmt); err != nil {
+				return err
+			}
+		case SPATIAL:
+			if err := p.parseTableSpatialIndex(ctx, stmt); err != nil {
+				return err
+			}
+		case FOREIGN:
+			if err := p.parseTableForeignKey(ctx, stmt); err != nil {
+				return err
+			}
+		case CHECK:
+			check, err := p.parseCheck(ctx)
+			if err != nil {
+				return err
+			}
+			stmt.Checks = append(stmt.Checks, check)
+		case IDENT, BACKTICK_IDENT:
+			if err := p.parseTableColumn(ctx, stmt, renamedFrom); err != nil {
+				return err
+			}
+		default:
+			return newParseError(ctx, t, "unexpected create table field token: %s", t.Type)
+		}
+
+		ctx.skipWhiteSpaces()
+		switch t := ctx.peek(); t.Type {
+		case RPAREN:
+			ctx.advance()
+			if err := p.parseCreateTableOptions(ctx, stmt); err != nil {
+				return err
+			}
+			// partition option
+			if !p.eol(ctx) {
+				return newParseError(ctx, t, "expected EOL")
+			}
+			return nil
+		case COMMA:
+			ctx.advance()
+			// Expecting another table field, keep looping
+		default:
+			return newParseError(ctx, t, "expected RPAREN or COMMA")
+		}
+	}
+}
+
+func (p *Parser) parseTableConstraint(ctx *parseCtx, table *model.Table) error {
+	if t := ctx.next(); t.Type != CONSTRAINT {
+		return newParseError(ctx, t, "expected CONSTRAINT")
+	}
+	ctx.skipWhiteSpaces()
+
+	var sym string
+	switch t := ctx.peek(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		// TODO: should be smarter
+		// (lestrrat): I don't understand. How?
+		sym = t.Value
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+	}
+
+	var index *model.Index
+	switch t := ctx.peek(); t.Type {
+	case CHECK:
+		check, err := p.parseCheck(ctx)
+		if err != nil {
+			return err
+		}
+		if len(sym) > 0 {
+			check.Name = model.MaybeIdent{
+				Ident: model.Ident(sym),
+				Valid: true,
+			}
+		}
+		table.Checks = append(table.Checks, check)
+		return nil
+	case PRIMARY:
+		index = model.NewIndex(model.IndexKindPrimaryKey, table.ID())
+		if err := p.parseColumnIndexPrimaryKey(ctx, index); err != nil {
+			return err
+		}
+	case UNIQUE:
+		index = model.NewIndex(model.IndexKindUnique, table.ID())
+		if err := p.parseColumnIndexUniqueKey(ctx, index); err != nil {
+			return err
+		}
+	case FOREIGN:
+		index = model.NewIndex(model.IndexKindForeignKey, table.ID())
+		if err := p.parseColumnIndexForeignKey(ctx, index); err != nil {
+			return err
+		}
+	default:
+		return newParseError(ctx, t, "not supported")
+	}
+
+	if len(sym) > 0 {
+		index.ConstraintName = model.MaybeIdent{
+			Ident: model.Ident(sym),
+			Valid: true,
+		}
+	}
+
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+// parseCheck parses CHECK (expr) [[NOT] ENFORCED].
+func (p *Parser) parseCheck(ctx *parseCtx) (*model.Check, error) {
+	if t := ctx.next(); t.Type != CHECK {
+		return nil, newParseError(ctx, t, "expected CHECK")
+	}
+	ctx.skipWhiteSpaces()
+	expr, err := p.parseParenthesizedExpr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	check := model.NewCheck(expr)
+
+	for {
+		switch t := ctx.peek(); t.Type {
+		case SPACE:
+			ctx.advance()
+			continue
+		case COMMENT_IDENT:
+			// SHOW CREATE TABLE shows it in an executable comment.
+			// e.g. CHECK ((`a` > 0)) /*!80016 NOT ENFORCED */
+			if v, ok := executableComment(t.Value); ok {
+				switch strings.ToUpper(strings.Join(strings.Fields(v), " ")) {
+				case "ENFORCED":
+					ctx.advance()
+				case "NOT ENFORCED":
+					ctx.advance()
+					check.NotEnforced = true
+				}
+			}
+		case NOT:
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			if t := ctx.next(); !isKeyword(t, "ENFORCED") {
+				return nil, newParseError(ctx, t, "expected ENFORCED")
+			}
+			check.NotEnforced = true
+		case IDENT:
+			if isKeyword(t, "ENFORCED") {
+				ctx.advance()
+			}
+		}
+		return check, nil
+	}
+}
+
+// executableComment returns the content of the MySQL-specific comment, such as /*!80016 NOT ENFORCED */.
+func executableComment(s string) (string, bool) {
+	if !strings.HasPrefix(s, "/*!") || !strings.HasSuffix(s, "*/") || len(s) < len("/*!*/") {
+		return "", false
+	}
+	s = s[len("/*!") : len(s)-len("*/")]
+	s = strings.TrimLeft(s, "0123456789")
+	return strings.TrimSpace(s), true
+}
+
+func (p *Parser) parseTablePrimaryKey(ctx *parseCtx, table *model.Table) error {
+	index := model.NewIndex(model.IndexKindPrimaryKey, table.ID())
+	if err := p.parseColumnIndexPrimaryKey(ctx, index); err != nil {
+		return err
+	}
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+func (p *Parser) parseTableUniqueKey(ctx *parseCtx, table *model.Table) error {
+	index := model.NewIndex(model.IndexKindUnique, table.ID())
+	if err := p.parseColumnIndexUniqueKey(ctx, index); err != nil {
+		return err
+	}
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+func (p *Parser) parseTableIndex(ctx *parseCtx, table *model.Table) error {
+	index := model.NewIndex(model.IndexKindNormal, table.ID())
+	if err := p.parseColumnIndexKey(ctx, index); err != nil {
+		return err
+	}
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+func (p *Parser) parseTableFulltextIndex(ctx *parseCtx, table *model.Table) error {
 	index := model.NewIndex(model.IndexKindFullText, table.ID())
 	if err := p.parseColumnIndexFullTextKey(ctx, index); err != nil {
 		return err
@@ -453,7 +1466,7 @@ func (p *Parser) parseTableForeignKey(ctx *parseCtx, table *model.Table) error {
 	return nil
 }
 
-func (p *Parser) parseTableColumn(ctx *parseCtx, table *model.Table) error {
+func (p *Parser) parseTableColumn(ctx *parseCtx, table *model.Table, renamedFrom model.MaybeIdent) error {
 	t := ctx.next()
 	switch t.Type {
 	case IDENT, BACKTICK_IDENT:
@@ -465,6 +1478,13 @@ func (p *Parser) parseTableColumn(ctx *parseCtx, table *model.Table) error {
 	if err := p.parseTableColumnSpec(ctx, col); err != nil {
 		return err
 	}
+
+	// the annotation may be in the column definition.
+	// e.g. `new_name INTEGER NOT NULL -- schemalex:renamed-from old_name`
+	if v := ctx.takeRenamedFrom(); v.Valid {
+		renamedFrom = v
+	}
+	col.RenamedFrom = renamedFrom
 	table.Columns = append(table.Columns, col)
 	return nil
 }
@@ -622,6 +1642,16 @@ func (p *Parser) parseTableColumnSpec(ctx *parseCtx, col *model.TableColumn) err
 }
 
 func (p *Parser) parseCreateTableOptionValue(ctx *parseCtx, table *model.Table, name string, follow ...TokenType) error {
+	opt, err := p.parseTableOptionValue(ctx, name, follow...)
+	if err != nil {
+		return err
+	}
+	table.Options = append(table.Options, opt)
+	return nil
+}
+
+// parseTableOptionValue parses `[=] value` of the table options and the partition options.
+func (p *Parser) parseTableOptionValue(ctx *parseCtx, name string, follow ...TokenType) (*model.TableOption, error) {
 	ctx.skipWhiteSpaces()
 	if t := ctx.peek(); t.Type == EQUAL {
 		ctx.advance()
@@ -638,10 +1668,9 @@ func (p *Parser) parseCreateTableOptionValue(ctx *parseCtx, table *model.Table,
 		case SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT:
 			quotes = true
 		}
-		table.Options = append(table.Options, model.NewTableOption(name, t.Value, quotes))
-		return nil
+		return model.NewTableOption(name, t.Value, quotes), nil
 	}
-	return newParseError(ctx, t, "expected %v", follow)
+	return nil, newParseError(ctx, t, "expected %v", follow)
 }
 
 func (p *Parser) parseCreateTableOptions(ctx *parseCtx, table *model.Table) error {
@@ -651,7 +1680,7 @@ func (p *Parser) parseCreateTableOptions(ctx *parseCtx, table *model.Table) erro
 		// no table options, end of input
 		ctx.advance()
 		return nil
-	case SEMICOLON:
+	case SEMICOLON, DELIMITER:
 		// no table options, end of statement
 		return nil
 	}
@@ -674,130 +1703,510 @@ func (p *Parser) parseCreateTableOptions(ctx *parseCtx, table *model.Table) erro
 		case DEFAULT:
 			var name string
 			ctx.skipWhiteSpaces()
-			switch t := ctx.next(); t.Type {
-			case CHARSET:
-				name = "DEFAULT CHARACTER SET"
-			case CHARACTER:
+			switch t := ctx.next(); t.Type {
+			case CHARSET:
+				name = "DEFAULT CHARACTER SET"
+			case CHARACTER:
+				ctx.skipWhiteSpaces()
+				if t := ctx.next(); t.Type != SET {
+					return newParseError(ctx, t, "expected SET")
+				}
+				name = "DEFAULT CHARACTER SET"
+			case COLLATE:
+				name = "DEFAULT COLLATE"
+			default:
+				return newParseError(ctx, t, "expected CHARACTER or COLLATE")
+			}
+			if err := p.parseCreateTableOptionValue(ctx, table, name, IDENT, BACKTICK_IDENT); err != nil {
+				return err
+			}
+		case CHARACTER:
+			ctx.skipWhiteSpaces()
+			if t := ctx.next(); t.Type != SET {
+				return newParseError(ctx, t, "expected SET")
+			}
+			if err := p.parseCreateTableOptionValue(ctx, table, "DEFAULT CHARACTER SET", IDENT, BACKTICK_IDENT); err != nil {
+				return err
+			}
+		case COLLATE:
+			if err := p.parseCreateTableOptionValue(ctx, table, "DEFAULT COLLATE", IDENT, BACKTICK_IDENT); err != nil {
+				return err
+			}
+		case CHECKSUM:
+			if err := p.parseCreateTableOptionValue(ctx, table, "CHECKSUM", NUMBER); err != nil {
+				return err
+			}
+		case COMMENT:
+			if err := p.parseCreateTableOptionValue(ctx, table, "COMMENT", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
+				return err
+			}
+		case CONNECTION:
+			if err := p.parseCreateTableOptionValue(ctx, table, "CONNECTION", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
+				return err
+			}
+		case DATA:
+			ctx.skipWhiteSpaces()
+			if t := ctx.next(); t.Type != DIRECTORY {
+				return newParseError(ctx, t, "expected DIRECTORY")
+			}
+			if err := p.parseCreateTableOptionValue(ctx, table, "DATA DIRECTORY", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
+				return err
+			}
+		case DELAY_KEY_WRITE:
+			if err := p.parseCreateTableOptionValue(ctx, table, "DELAY_KEY_WRITE", NUMBER); err != nil {
+				return err
+			}
+		case INDEX:
+			ctx.skipWhiteSpaces()
+			if t := ctx.next(); t.Type != DIRECTORY {
+				return newParseError(ctx, t, "should DIRECTORY")
+			}
+			if err := p.parseCreateTableOptionValue(ctx, table, "INDEX DIRECTORY", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
+				return err
+			}
+		case INSERT_METHOD:
+			if err := p.parseCreateTableOptionValue(ctx, table, "INSERT_METHOD", NO, FIRST, LAST); err != nil {
+				return err
+			}
+		case KEY_BLOCK_SIZE:
+			if err := p.parseCreateTableOptionValue(ctx, table, "KEY_BLOCK_SIZE", NUMBER); err != nil {
+				return err
+			}
+		case MAX_ROWS:
+			if err := p.parseCreateTableOptionValue(ctx, table, "MAX_ROWS", NUMBER); err != nil {
+				return err
+			}
+		case MIN_ROWS:
+			if err := p.parseCreateTableOptionValue(ctx, table, "MIN_ROWS", NUMBER); err != nil {
+				return err
+			}
+		case PACK_KEYS:
+			if err := p.parseCreateTableOptionValue(ctx, table, "PACK_KEYS", NUMBER, IDENT); err != nil {
+				return err
+			}
+		case PASSWORD:
+			if err := p.parseCreateTableOptionValue(ctx, table, "PASSWORD", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
+				return err
+			}
+		case ROW_FORMAT:
+			if err := p.parseCreateTableOptionValue(ctx, table, "ROW_FORMAT", DEFAULT, DYNAMIC, FIXED, COMPRESSED, REDUNDANT, COMPACT); err != nil {
+				return err
+			}
+		case STATS_AUTO_RECALC:
+			if err := p.parseCreateTableOptionValue(ctx, table, "STATS_AUTO_RECALC", NUMBER, DEFAULT); err != nil {
+				return err
+			}
+		case STATS_PERSISTENT:
+			if err := p.parseCreateTableOptionValue(ctx, table, "STATS_PERSISTENT", NUMBER, DEFAULT); err != nil {
+				return err
+			}
+		case STATS_SAMPLE_PAGES:
+			if err := p.parseCreateTableOptionValue(ctx, table, "STATS_SAMPLE_PAGES", NUMBER); err != nil {
+				return err
+			}
+		case STORAGE:
+			if err := p.parseCreateTableOptionValue(ctx, table, "STORAGE", DISK, MEMORY); err != nil {
+				return err
+			}
+		case TABLESPACE:
+			opt, err := p.parseTableOptionValue(ctx, "TABLESPACE", IDENT, BACKTICK_IDENT)
+			if err != nil {
+				return err
+			}
+			opt.Value = model.Ident(opt.Value).Quoted()
+			table.Options = append(table.Options, opt)
+		case UNION:
+			if err := p.parseUnionTables(ctx, table); err != nil {
+				return err
+			}
+		case COMMA:
+			// no op, continue to next option
+			continue
+		case IDENT:
+			if !isKeyword(t, "PARTITION") {
+				return newParseError(ctx, t, "unexpected token in table options: "+t.Type.String())
+			}
+			if err := p.parsePartition(ctx, table); err != nil {
+				return err
+			}
+		default:
+			return newParseError(ctx, t, "unexpected token in table options: "+t.Type.String())
+		}
+
+		ctx.skipWhiteSpaces()
+		// except for the case where we continue to the next option (COMMA)
+		// we should expect the end of this statement
+		switch t := ctx.peek(); t.Type {
+		case EOF:
+			// end of table options, end of input
+			ctx.advance()
+			return nil
+		case SEMICOLON, DELIMITER:
+			// end of table options, end of statement
+			return nil
+		}
+	}
+}
+
+// parseUnionTables parses the table list of MERGE tables after `UNION`, such as `UNION = (t1, t2)`.
+// The list is stored as the value of the table option.
+func (p *Parser) parseUnionTables(ctx *parseCtx, table *model.Table) error {
+	ctx.skipWhiteSpaces()
+	if t := ctx.peek(); t.Type == EQUAL {
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+	}
+	if t := ctx.next(); t.Type != LPAREN {
+		return newParseError(ctx, t, "expected LPAREN")
+	}
+
+	var names []string
+	for {
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case IDENT, BACKTICK_IDENT:
+			names = append(names, t.Ident().Quoted())
+		default:
+			return newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+		}
+
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case COMMA:
+		case RPAREN:
+			table.Options = append(table.Options, model.NewTableOption("UNION", "("+strings.Join(names, ", ")+")", false))
+			return nil
+		default:
+			return newParseError(ctx, t, "expected COMMA or RPAREN")
+		}
+	}
+}
+
+// parseTimestampFunc parses CURRENT_TIMESTAMP [([fsp])] or NOW([fsp]).
+// t is the CURRENT_TIMESTAMP or NOW token that is already read.
+func (p *Parser) parseTimestampFunc(ctx *parseCtx, t *Token) (string, error) {
+	name := strings.ToUpper(t.Value)
+	if t.Type == CURRENT_TIMESTAMP && ctx.peek().Type != LPAREN {
+		return name, nil
+	}
+	if t := ctx.next(); t.Type != LPAREN {
+		return "", newParseError(ctx, t, "expected LPAREN")
+	}
+	ctx.skipWhiteSpaces()
+	var fsp string
+	if t := ctx.peek(); t.Type == NUMBER {
+		ctx.advance()
+		fsp = t.Value
+		ctx.skipWhiteSpaces()
+	}
+	if t := ctx.next(); t.Type != RPAREN {
+		return "", newParseError(ctx, t, "expected RPAREN")
+	}
+	return name + "(" + fsp + ")", nil
+}
+
+// isKeyword returns whether t is the keyword that is not reserved by the lexer, such as PARTITION.
+func isKeyword(t *Token, word string) bool {
+	return t.Type == IDENT && strings.EqualFold(t.Value, word)
+}
+
+// parsePartition parses the partition options after `PARTITION`.
+// https://dev.mysql.com/doc/refman/8.0/en/create-table.html#create-table-partitioning
+func (p *Parser) parsePartition(ctx *parseCtx, table *model.Table) error {
+	ctx.skipWhiteSpaces()
+	if t := ctx.next(); !isKeyword(t, "BY") {
+		return newParseError(ctx, t, "expected BY")
+	}
+	part, err := p.parsePartitionType(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	// PARTITIONS num
+	ctx.skipWhiteSpaces()
+	if t := ctx.peek(); isKeyword(t, "PARTITIONS") {
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+		t := ctx.next()
+		if t.Type != NUMBER {
+			return newParseError(ctx, t, "expected NUMBER")
+		}
+		part.Num = model.MaybeString{Valid: true, Value: t.Value}
+	}
+
+	// SUBPARTITION BY ... [SUBPARTITIONS num]
+	ctx.skipWhiteSpaces()
+	if t := ctx.peek(); isKeyword(t, "SUBPARTITION") {
+		ctx.advance()
+		ctx.skipWhiteSpaces()
+		if t := ctx.next(); !isKeyword(t, "BY") {
+			return newParseError(ctx, t, "expected BY")
+		}
+		sub, err := p.parsePartitionType(ctx, true)
+		if err != nil {
+			return err
+		}
+		ctx.skipWhiteSpaces()
+		if t := ctx.peek(); isKeyword(t, "SUBPARTITIONS") {
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			t := ctx.next()
+			if t.Type != NUMBER {
+				return newParseError(ctx, t, "expected NUMBER")
+			}
+			sub.Num = model.MaybeString{Valid: true, Value: t.Value}
+		}
+		part.SubPartition = sub
+	}
+
+	// (partition_definition [, partition_definition] ...)
+	ctx.skipWhiteSpaces()
+	if t := ctx.peek(); t.Type == LPAREN {
+		ctx.advance()
+		defs, err := p.parsePartitionDefinitions(ctx, false)
+		if err != nil {
+			return err
+		}
+		part.Definitions = defs
+	}
+
+	table.Partition = part
+	return nil
+}
+
+// parsePartitionType parses the type of partitioning, such as `RANGE (expr)` and `LINEAR KEY (columns)`.
+// The subpartitioning supports only HASH and KEY.
+func (p *Parser) parsePartitionType(ctx *parseCtx, sub bool) (*model.Partition, error) {
+	ctx.skipWhiteSpaces()
+	t := ctx.next()
+	var linear bool
+	if isKeyword(t, "LINEAR") {
+		linear = true
+		ctx.skipWhiteSpaces()
+		t = ctx.next()
+	}
+
+	var part *model.Partition
+	switch {
+	case t.Type == HASH:
+		part = model.NewPartition(model.PartitionTypeHash)
+		ctx.skipWhiteSpaces()
+		expr, err := p.parseParenthesizedExpr(ctx)
+		if err != nil {
+			return nil, err
+		}
+		part.Expr = expr
+	case t.Type == KEY:
+		part = model.NewPartition(model.PartitionTypeKey)
+		ctx.skipWhiteSpaces()
+		if t := ctx.peek(); isKeyword(t, "ALGORITHM") {
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			if t := ctx.next(); t.Type != EQUAL {
+				return nil, newParseError(ctx, t, "expected EQUAL")
+			}
+			ctx.skipWhiteSpaces()
+			t := ctx.next()
+			if t.Type != NUMBER {
+				return nil, newParseError(ctx, t, "expected NUMBER")
+			}
+			part.Algorithm = model.MaybeString{Valid: true, Value: t.Value}
+		}
+		cols, err := p.parsePartitionColumns(ctx)
+		if err != nil {
+			return nil, err
+		}
+		part.Columns = cols
+	case !linear && !sub && (isKeyword(t, "RANGE") || isKeyword(t, "LIST")):
+		isRange := isKeyword(t, "RANGE")
+		ctx.skipWhiteSpaces()
+		if t := ctx.peek(); isKeyword(t, "COLUMNS") {
+			ctx.advance()
+			part = model.NewPartition(model.PartitionTypeListColumns)
+			if isRange {
+				part.Type = model.PartitionTypeRangeColumns
+			}
+			cols, err := p.parsePartitionColumns(ctx)
+			if err != nil {
+				return nil, err
+			}
+			part.Columns = cols
+		} else {
+			part = model.NewPartition(model.PartitionTypeList)
+			if isRange {
+				part.Type = model.PartitionTypeRange
+			}
+			expr, err := p.parseParenthesizedExpr(ctx)
+			if err != nil {
+				return nil, err
+			}
+			part.Expr = expr
+		}
+	default:
+		if sub {
+			return nil, newParseError(ctx, t, "expected HASH or KEY")
+		}
+		return nil, newParseError(ctx, t, "expected HASH, KEY, RANGE or LIST")
+	}
+	part.Linear = linear
+	return part, nil
+}
+
+// parsePartitionColumns parses the list of the columns for partitioning.
+// The list may be empty for KEY partitioning, which means the primary key.
+func (p *Parser) parsePartitionColumns(ctx *parseCtx) ([]model.Ident, error) {
+	ctx.skipWhiteSpaces()
+	if t := ctx.next(); t.Type != LPAREN {
+		return nil, newParseError(ctx, t, "expected LPAREN")
+	}
+
+	var cols []model.Ident
+	ctx.skipWhiteSpaces()
+	if t := ctx.peek(); t.Type == RPAREN {
+		ctx.advance()
+		return cols, nil
+	}
+	for {
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case IDENT, BACKTICK_IDENT:
+			cols = append(cols, t.Ident())
+		default:
+			return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+		}
+
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case COMMA:
+		case RPAREN:
+			return cols, nil
+		default:
+			return nil, newParseError(ctx, t, "expected COMMA or RPAREN")
+		}
+	}
+}
+
+// parsePartitionDefinitions parses the partition definitions after `(`.
+func (p *Parser) parsePartitionDefinitions(ctx *parseCtx, sub bool) ([]*model.PartitionDefinition, error) {
+	var defs []*model.PartitionDefinition
+	for {
+		def, err := p.parsePartitionDefinition(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+
+		ctx.skipWhiteSpaces()
+		switch t := ctx.next(); t.Type {
+		case COMMA:
+		case RPAREN:
+			return defs, nil
+		default:
+			return nil, newParseError(ctx, t, "expected COMMA or RPAREN")
+		}
+	}
+}
+
+// parsePartitionDefinition parses a partition definition or a subpartition definition.
+func (p *Parser) parsePartitionDefinition(ctx *parseCtx, sub bool) (*model.PartitionDefinition, error) {
+	keyword := "PARTITION"
+	if sub {
+		keyword = "SUBPARTITION"
+	}
+	ctx.skipWhiteSpaces()
+	if t := ctx.next(); !isKeyword(t, keyword) {
+		return nil, newParseError(ctx, t, "expected %s", keyword)
+	}
+
+	ctx.skipWhiteSpaces()
+	var def *model.PartitionDefinition
+	switch t := ctx.next(); t.Type {
+	case IDENT, BACKTICK_IDENT:
+		def = model.NewPartitionDefinition(t.Ident())
+	default:
+		return nil, newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	}
+
+	for {
+		ctx.skipWhiteSpaces()
+		t := ctx.peek()
+		var name string
+		var follow []TokenType
+		switch {
+		case !sub && isKeyword(t, "VALUES"):
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			switch t := ctx.next(); {
+			case isKeyword(t, "LESS"):
 				ctx.skipWhiteSpaces()
-				if t := ctx.next(); t.Type != SET {
-					return newParseError(ctx, t, "expected SET")
+				if t := ctx.next(); !isKeyword(t, "THAN") {
+					return nil, newParseError(ctx, t, "expected THAN")
 				}
-				name = "DEFAULT CHARACTER SET"
-			case COLLATE:
-				name = "DEFAULT COLLATE"
+				ctx.skipWhiteSpaces()
+				if t := ctx.peek(); isKeyword(t, "MAXVALUE") {
+					ctx.advance()
+					def.Values = model.MaybeString{Valid: true, Value: "LESS THAN MAXVALUE"}
+					continue
+				}
+				expr, err := p.parseParenthesizedExpr(ctx)
+				if err != nil {
+					return nil, err
+				}
+				def.Values = model.MaybeString{Valid: true, Value: "LESS THAN (" + expr + ")"}
+			case isKeyword(t, "IN"):
+				ctx.skipWhiteSpaces()
+				expr, err := p.parseParenthesizedExpr(ctx)
+				if err != nil {
+					return nil, err
+				}
+				def.Values = model.MaybeString{Valid: true, Value: "IN (" + expr + ")"}
 			default:
-				return newParseError(ctx, t, "expected CHARACTER or COLLATE")
-			}
-			if err := p.parseCreateTableOptionValue(ctx, table, name, IDENT, BACKTICK_IDENT); err != nil {
-				return err
-			}
-		case CHARACTER:
-			ctx.skipWhiteSpaces()
-			if t := ctx.next(); t.Type != SET {
-				return newParseError(ctx, t, "expected SET")
-			}
-			if err := p.parseCreateTableOptionValue(ctx, table, "DEFAULT CHARACTER SET", IDENT, BACKTICK_IDENT); err != nil {
-				return err
-			}
-		case COLLATE:
-			if err := p.parseCreateTableOptionValue(ctx, table, "DEFAULT COLLATE", IDENT, BACKTICK_IDENT); err != nil {
-				return err
-			}
-		case CHECKSUM:
-			if err := p.parseCreateTableOptionValue(ctx, table, "CHECKSUM", NUMBER); err != nil {
-				return err
-			}
-		case COMMENT:
-			if err := p.parseCreateTableOptionValue(ctx, table, "COMMENT", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
-				return err
+				return nil, newParseError(ctx, t, "expected LESS THAN or IN")
 			}
-		case CONNECTION:
-			if err := p.parseCreateTableOptionValue(ctx, table, "CONNECTION", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
-				return err
+			continue
+		case !sub && t.Type == LPAREN:
+			ctx.advance()
+			subs, err := p.parsePartitionDefinitions(ctx, true)
+			if err != nil {
+				return nil, err
 			}
-		case DATA:
+			def.SubPartitions = subs
+			continue
+		case t.Type == STORAGE:
+			ctx.advance()
 			ctx.skipWhiteSpaces()
-			if t := ctx.next(); t.Type != DIRECTORY {
-				return newParseError(ctx, t, "expected DIRECTORY")
-			}
-			if err := p.parseCreateTableOptionValue(ctx, table, "DATA DIRECTORY", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
-				return err
-			}
-		case DELAY_KEY_WRITE:
-			if err := p.parseCreateTableOptionValue(ctx, table, "DATA_KEY_WRITE", NUMBER); err != nil {
-				return err
+			if t := ctx.next(); t.Type != ENGINE {
+				return nil, newParseError(ctx, t, "expected ENGINE")
 			}
-		case INDEX:
+			name, follow = "ENGINE", []TokenType{IDENT, BACKTICK_IDENT}
+		case t.Type == ENGINE:
+			ctx.advance()
+			name, follow = "ENGINE", []TokenType{IDENT, BACKTICK_IDENT}
+		case t.Type == COMMENT:
+			ctx.advance()
+			name, follow = "COMMENT", []TokenType{SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT}
+		case t.Type == DATA || t.Type == INDEX:
+			ctx.advance()
 			ctx.skipWhiteSpaces()
 			if t := ctx.next(); t.Type != DIRECTORY {
-				return newParseError(ctx, t, "should DIRECTORY")
-			}
-			if err := p.parseCreateTableOptionValue(ctx, table, "INDEX DIRECTORY", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
-				return err
-			}
-		case INSERT_METHOD:
-			if err := p.parseCreateTableOptionValue(ctx, table, "INSERT_METHOD", IDENT); err != nil {
-				return err
-			}
-		case KEY_BLOCK_SIZE:
-			if err := p.parseCreateTableOptionValue(ctx, table, "KEY_BLOCK_SIZE", NUMBER); err != nil {
-				return err
-			}
-		case MAX_ROWS:
-			if err := p.parseCreateTableOptionValue(ctx, table, "MAX_ROWS", NUMBER); err != nil {
-				return err
-			}
-		case MIN_ROWS:
-			if err := p.parseCreateTableOptionValue(ctx, table, "MIN_ROWS", NUMBER); err != nil {
-				return err
-			}
-		case PACK_KEYS:
-			if err := p.parseCreateTableOptionValue(ctx, table, "PACK_KEYS", NUMBER, IDENT); err != nil {
-				return err
-			}
-		case PASSWORD:
-			if err := p.parseCreateTableOptionValue(ctx, table, "PASSWORD", SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT); err != nil {
-				return err
-			}
-		case ROW_FORMAT:
-			if err := p.parseCreateTableOptionValue(ctx, table, "ROW_FORMAT", DEFAULT, DYNAMIC, FIXED, COMPRESSED, REDUNDANT, COMPACT); err != nil {
-				return err
-			}
-		case STATS_AUTO_RECALC:
-			if err := p.parseCreateTableOptionValue(ctx, table, "STATS_AUTO_RECALC", NUMBER, DEFAULT); err != nil {
-				return err
-			}
-		case STATS_PERSISTENT:
-			if err := p.parseCreateTableOptionValue(ctx, table, "STATS_PERSISTENT", NUMBER, DEFAULT); err != nil {
-				return err
-			}
-		case STATS_SAMPLE_PAGES:
-			if err := p.parseCreateTableOptionValue(ctx, table, "STATS_SAMPLE_PAGES", NUMBER); err != nil {
-				return err
+				return nil, newParseError(ctx, t, "expected DIRECTORY")
 			}
-		case TABLESPACE:
-			return newParseError(ctx, t, "unsupported option TABLESPACE")
-		case UNION:
-			return newParseError(ctx, t, "unsupported option UNION")
-		case COMMA:
-			// no op, continue to next option
-			continue
+			name, follow = strings.ToUpper(t.Value)+" DIRECTORY", []TokenType{SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT}
+		case t.Type == MAX_ROWS || t.Type == MIN_ROWS:
+			ctx.advance()
+			name, follow = strings.ToUpper(t.Value), []TokenType{NUMBER}
+		case t.Type == TABLESPACE:
+			ctx.advance()
+			name, follow = "TABLESPACE", []TokenType{IDENT, BACKTICK_IDENT}
 		default:
-			return newParseError(ctx, t, "unexpected token in table options: "+t.Type.String())
+			return def, nil
 		}
 
-		ctx.skipWhiteSpaces()
-		// except for the case where we continue to the next option (COMMA)
-		// we should expect the end of this statement
-		switch t := ctx.peek(); t.Type {
-		case EOF:
-			// end of table options, end of input
-			ctx.advance()
-			return nil
-		case SEMICOLON:
-			// end of table options, end of statement
-			return nil
+		opt, err := p.parseTableOptionValue(ctx, name, follow...)
+		if err != nil {
+			return nil, err
 		}
+		def.Options = append(def.Options, opt)
 	}
 }
 
@@ -808,7 +2217,7 @@ func (p *Parser) parseCreateTableOptions(ctx *parseCtx, table *model.Table) erro
 // column options, although the docs (https://dev.mysql.com/doc/refman/5.7/en/create-table.html)
 // seem to state otherwise.
 func (p *Parser) parseColumnOption(ctx *parseCtx, col *model.TableColumn, f int) error {
-	f = f | coloptNull | coloptDefault | coloptAutoIncrement | coloptKey | coloptComment
+	f = f | coloptGenerated | coloptNull | coloptDefault | coloptAutoIncrement | coloptKey | coloptCheck | coloptComment | coloptVisibility
 	pos := 0
 	check := func(_f int) bool {
 		if pos > _f {
@@ -948,8 +2357,16 @@ func (p *Parser) parseColumnOption(ctx *parseCtx, col *model.TableColumn, f int)
 			}
 			ctx.skipWhiteSpaces()
 			v := ctx.next()
+			value := v.Value
+			if v.Type == CURRENT_TIMESTAMP || v.Type == NOW {
+				var err error
+				value, err = p.parseTimestampFunc(ctx, v)
+				if err != nil {
+					return err
+				}
+			}
 			col.AutoUpdate.Valid = true
-			col.AutoUpdate.Value = v.Value
+			col.AutoUpdate.Value = value
 		case DEFAULT:
 			if !check(coloptDefault) {
 				return newParseError(ctx, t, "cannot apply DEFAULT")
@@ -960,23 +2377,39 @@ func (p *Parser) parseColumnOption(ctx *parseCtx, col *model.TableColumn, f int)
 				col.Default.Valid = true
 				col.Default.Value = t.Value
 				col.Default.Quoted = true
-			case NUMBER, CURRENT_TIMESTAMP, NULL, TRUE, FALSE:
+			case NUMBER, NULL, TRUE, FALSE:
 				col.Default.Valid = true
 				col.Default.Value = strings.ToUpper(t.Value)
 				col.Default.Quoted = false
-			case NOW:
-				now := t.Value
-				if t := ctx.next(); t.Type != LPAREN {
-					return newParseError(ctx, t, "expected LPAREN")
+			case CURRENT_TIMESTAMP, NOW:
+				value, err := p.parseTimestampFunc(ctx, t)
+				if err != nil {
+					return err
 				}
-				if t := ctx.next(); t.Type != RPAREN {
-					return newParseError(ctx, t, "expected RPAREN")
+				col.Default.Valid = true
+				col.Default.Value = value
+				col.Default.Quoted = false
+			case LPAREN:
+				// DEFAULT (expr)
+				ctx.rewind()
+				expr, err := p.parseParenthesizedExpr(ctx)
+				if err != nil {
+					return err
 				}
 				col.Default.Valid = true
-				col.Default.Value = strings.ToUpper(now) + "()"
+				col.Default.Value = expr
 				col.Default.Quoted = false
+				col.Default.Expr = true
 			default:
-				return newParseError(ctx, t, "expected IDENT, SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT, NUMBER, CURRENT_TIMESTAMP, NULL")
+				return newParseError(ctx, t, "expected IDENT, SINGLE_QUOTE_IDENT, DOUBLE_QUOTE_IDENT, NUMBER, CURRENT_TIMESTAMP, NULL, LPAREN")
+			}
+		case AS:
+			// AS (expr) [VIRTUAL | STORED]
+			if !check(coloptGenerated) {
+				return newParseError(ctx, t, "cannot apply GENERATED ALWAYS AS")
+			}
+			if err := p.parseGeneratedColumn(ctx, col); err != nil {
+				return err
 			}
 		case AUTO_INCREMENT:
 			if !check(coloptAutoIncrement) {
@@ -1019,6 +2452,31 @@ func (p *Parser) parseColumnOption(ctx *parseCtx, col *model.TableColumn, f int)
 				return newParseError(ctx, t, "should SINGLE_QUOTE_IDENT")
 			}
 
+		case CONSTRAINT, CHECK:
+			// [CONSTRAINT [symbol]] CHECK (expr) [[NOT] ENFORCED]
+			if !check(coloptCheck) {
+				return newParseError(ctx, t, "cannot apply CHECK")
+			}
+			var sym model.MaybeIdent
+			if t.Type == CONSTRAINT {
+				ctx.skipWhiteSpaces()
+				switch t := ctx.peek(); t.Type {
+				case IDENT, BACKTICK_IDENT:
+					sym.Valid = true
+					sym.Ident = t.Ident()
+					ctx.advance()
+					ctx.skipWhiteSpaces()
+				}
+			} else {
+				ctx.rewind()
+			}
+			c, err := p.parseCheck(ctx)
+			if err != nil {
+				return err
+			}
+			c.Name = sym
+			col.Checks = append(col.Checks, c)
+
 		case SRID:
 			ctx.skipWhiteSpaces()
 			switch t := ctx.next(); t.Type {
@@ -1033,6 +2491,31 @@ func (p *Parser) parseColumnOption(ctx *parseCtx, col *model.TableColumn, f int)
 				return newParseError(ctx, t, "should NUMBER")
 			}
 
+		case IDENT:
+			switch {
+			case isKeyword(t, "GENERATED"):
+				// GENERATED ALWAYS AS (expr) [VIRTUAL | STORED]
+				if !check(coloptGenerated) {
+					return newParseError(ctx, t, "cannot apply GENERATED ALWAYS AS")
+				}
+				if err := p.parseKeywords(ctx, "ALWAYS"); err != nil {
+					return err
+				}
+				if _, err := p.parseIdents(ctx, AS); err != nil {
+					return err
+				}
+				if err := p.parseGeneratedColumn(ctx, col); err != nil {
+					return err
+				}
+			case isKeyword(t, "VISIBLE"), isKeyword(t, "INVISIBLE"):
+				if !check(coloptVisibility) {
+					return newParseError(ctx, t, "cannot apply %s", strings.ToUpper(t.Value))
+				}
+				col.Invisible = isKeyword(t, "INVISIBLE")
+			default:
+				return newParseError(ctx, t, "unexpected column option %s", t.Type)
+			}
+
 		case COMMA:
 			ctx.rewind()
 			return nil
@@ -1045,6 +2528,57 @@ func (p *Parser) parseColumnOption(ctx *parseCtx, col *model.TableColumn, f int)
 	}
 }
 
+// parseGeneratedColumn parses `(expr) [VIRTUAL | STORED]` after `[GENERATED ALWAYS] AS`.
+func (p *Parser) parseGeneratedColumn(ctx *parseCtx, col *model.TableColumn) error {
+	ctx.skipWhiteSpaces()
+	expr, err := p.parseParenthesizedExpr(ctx)
+	if err != nil {
+		return err
+	}
+	col.Generated.Valid = true
+	col.Generated.Value = expr
+
+	ctx.skipWhiteSpaces()
+	switch t := ctx.peek(); {
+	case isKeyword(t, "VIRTUAL"):
+		ctx.advance()
+		col.GeneratedStorage = model.GeneratedStorageVirtual
+	case isKeyword(t, "STORED"):
+		ctx.advance()
+		col.GeneratedStorage = model.GeneratedStorageStored
+	}
+	return nil
+}
+
+// parseParenthesizedExpr parses an expression surrounded by parentheses,
+// and returns the raw text of the expression without the parentheses.
+func (p *Parser) parseParenthesizedExpr(ctx *parseCtx) (string, error) {
+	begin := ctx.next()
+	if begin.Type != LPAREN {
+		return "", newParseError(ctx, begin, "expected LPAREN")
+	}
+
+	depth := 1
+	for {
+		t := ctx.next()
+		switch t.Type {
+		case LPAREN:
+			depth++
+		case RPAREN:
+			depth--
+			if depth == 0 {
+				expr := strings.TrimSpace(string(ctx.input[begin.Pos+1 : t.Pos]))
+				if expr == "" {
+					return "", newParseError(ctx, t, "expected expression")
+				}
+				return expr, nil
+			}
+		case EOF:
+			return "", newParseError(ctx, t, "expected RPAREN")
+		}
+	}
+}
+
 func (ctx *parseCtx) parseSetOrEnum(setter func([]string) *model.TableColumn) error {
 	var values []string
 OUTER:
@@ -1121,9 +2655,28 @@ func (p *Parser) parseColumnIndexCommon(ctx *parseCtx, index *model.Index) error
 		return err
 	}
 
+	p.parseIndexVisibility(ctx, index)
+
 	return nil
 }
 
+// parseIndexVisibility parses optional VISIBLE or INVISIBLE.
+// SHOW CREATE TABLE shows it in the executable comment, e.g. `/*!80000 INVISIBLE */`.
+func (p *Parser) parseIndexVisibility(ctx *parseCtx, index *model.Index) bool {
+	ctx.skipWhiteSpaces()
+	switch t := ctx.peek(); {
+	case isKeyword(t, "VISIBLE"):
+		ctx.advance()
+		index.Invisible = false
+		return true
+	case isKeyword(t, "INVISIBLE"):
+		ctx.advance()
+		index.Invisible = true
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseColumnIndexKey(ctx *parseCtx, index *model.Index) error {
 	switch t := ctx.next(); t.Type {
 	case KEY, INDEX:
@@ -1252,12 +2805,12 @@ func (p *Parser) parseColumnReference(ctx *parseCtx, index *model.Index) error {
 	r := model.NewReference()
 
 	ctx.skipWhiteSpaces()
-	switch t := ctx.next(); t.Type {
-	case BACKTICK_IDENT, IDENT:
-		r.TableName = t.Ident()
-	default:
-		return newParseError(ctx, t, "expected IDENT or BACKTICK_IDENT")
+	schema, name, err := p.parseTableName(ctx)
+	if err != nil {
+		return err
 	}
+	r.TableSchema = schema
+	r.TableName = name
 
 	cols, err := p.parseColumnIndexColumns(ctx)
 	if err != nil {
@@ -1368,16 +2921,29 @@ OUTER:
 	for {
 		ctx.skipWhiteSpaces()
 		t := ctx.next()
-		if !(t.Type == IDENT || t.Type == BACKTICK_IDENT) {
-			return nil, newParseError(ctx, t, "should IDENT or BACKTICK_IDENT")
+		var col *model.IndexColumn
+		switch t.Type {
+		case IDENT, BACKTICK_IDENT:
+			col = model.NewIndexColumn(model.Ident(t.Value))
+		case LPAREN:
+			// functional key part: (expr)
+			ctx.rewind()
+			expr, err := p.parseParenthesizedExpr(ctx)
+			if err != nil {
+				return nil, err
+			}
+			col = model.NewIndexExprColumn(expr)
+		default:
+			return nil, newParseError(ctx, t, "should IDENT, BACKTICK_IDENT or LPAREN")
 		}
-
-		col := model.NewIndexColumn(model.Ident(t.Value))
 		cols = append(cols, col)
 
 		ctx.skipWhiteSpaces()
 		switch t = ctx.next(); t.Type {
 		case LPAREN:
+			if col.Expr.Valid {
+				return nil, newParseError(ctx, t, "unexpected LPAREN")
+			}
 			t := ctx.next()
 			if t.Type != NUMBER {
 				return nil, newParseError(ctx, t, "expected NUMBER")
@@ -1419,27 +2985,32 @@ OUTER:
 }
 
 func (p *Parser) parseColumnIndexOptions(ctx *parseCtx, index *model.Index) error {
-	ctx.skipWhiteSpaces()
-	t := ctx.peek()
-	if t.Type == RPAREN {
-		return nil
-	}
-	// TODO: support for other index options.
-	switch t.Type {
-	case WITH:
-		ctx.advance()
+	for {
 		ctx.skipWhiteSpaces()
-		if t := ctx.peek(); t.Type != PARSER {
-			return newParseError(ctx, t, "expeected PARSER")
+		t := ctx.peek()
+		switch t.Type {
+		case RPAREN, COMMA:
+			return nil
 		}
-		ctx.advance()
-		if err := p.parseColumnIndexOptionValue(ctx, index, "WITH PARSER", IDENT, BACKTICK_IDENT); err != nil {
-			return err
+		if p.parseIndexVisibility(ctx, index) {
+			continue
+		}
+		// TODO: support for other index options.
+		switch t.Type {
+		case WITH:
+			ctx.advance()
+			ctx.skipWhiteSpaces()
+			if t := ctx.peek(); t.Type != PARSER {
+				return newParseError(ctx, t, "expeected PARSER")
+			}
+			ctx.advance()
+			if err := p.parseColumnIndexOptionValue(ctx, index, "WITH PARSER", IDENT, BACKTICK_IDENT); err != nil {
+				return err
+			}
+		default:
+			return newParseError(ctx, t, "unsupported type in index option specification")
 		}
-	default:
-		return newParseError(ctx, t, "unsupported type in index option specification")
 	}
-	return nil
 }
 
 func (p *Parser) parseColumnIndexOptionValue(ctx *parseCtx, index *model.Index, name string, follow ...TokenType) error {
@@ -1466,7 +3037,20 @@ func (p *Parser) parseColumnIndexOptionValue(ctx *parseCtx, index *model.Index,
 func (pctx *parseCtx) skipWhiteSpaces() {
 	for {
 		switch t := pctx.peek(); t.Type {
-		case SPACE, COMMENT_IDENT:
+		case SPACE:
+			pctx.advance()
+			continue
+		case COMMENT_IDENT:
+			if isFileAnnotation(t.Value) {
+				// the current database doesn't continue to the next file.
+				pctx.database = ""
+			}
+			if name, ok := parseRenamedFrom(t.Value); ok {
+				pctx.renamedFrom = model.MaybeIdent{
+					Ident: name,
+					Valid: true,
+				}
+			}
 			pctx.advance()
 			continue
 		default:
@@ -1475,6 +3059,14 @@ func (pctx *parseCtx) skipWhiteSpaces() {
 	}
 }
 
+// takeRenamedFrom returns the renamed-from annotation found in the skipped comments,
+// and clears it.
+func (pctx *parseCtx) takeRenamedFrom() model.MaybeIdent {
+	v := pctx.renamedFrom
+	pctx.renamedFrom = model.MaybeIdent{}
+	return v
+}
+
 func (p *Parser) parseIdents(ctx *parseCtx, idents ...TokenType) ([]string, error) {
 	strs := []string{}
 	for _, ident := range idents {
@@ -1488,11 +3080,22 @@ func (p *Parser) parseIdents(ctx *parseCtx, idents ...TokenType) ([]string, erro
 	return strs, nil
 }
 
+// parseKeywords parses the sequence of the non-reserved keywords, which are lexed as IDENT.
+func (p *Parser) parseKeywords(ctx *parseCtx, words ...string) error {
+	for _, word := range words {
+		ctx.skipWhiteSpaces()
+		if t := ctx.next(); !isKeyword(t, word) {
+			return newParseError(ctx, t, "expected %s", word)
+		}
+	}
+	return nil
+}
+
 // TODO: revisit what exactly this eol is meant to do
 func (p *Parser) eol(ctx *parseCtx) bool {
 	ctx.skipWhiteSpaces()
 	switch t := ctx.next(); t.Type {
-	case EOF, SEMICOLON:
+	case EOF, SEMICOLON, DELIMITER:
 		ctx.advance()
 		return true
 	default:
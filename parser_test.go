@@ -207,6 +207,245 @@ func TestParse1(t *testing.T) {
 				},
 			},
 		},
+		{
+			src: "-- schemalex:renamed-from hoge\n" +
+				"CREATE TABLE `fuga` (\n" +
+				"-- schemalex:renamed-from old_a\n" +
+				"`a` INTEGER NOT NULL,\n" +
+				"# schemalex:renamed-from `old_b`\n" +
+				"`b` INTEGER NOT NULL,\n" +
+				"`c` INTEGER NOT NULL /* schemalex:renamed-from old_c */,\n" +
+				"-- schemalex:renamed-from-typo old_d\n" +
+				"`d` INTEGER NOT NULL\n" +
+				");",
+			want: model.Stmts{
+				&model.Table{
+					Name: "fuga",
+					RenamedFrom: model.MaybeIdent{
+						Valid: true,
+						Ident: "hoge",
+					},
+					Columns: []*model.TableColumn{
+						{
+							Name:      "a",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+							RenamedFrom: model.MaybeIdent{
+								Valid: true,
+								Ident: "old_a",
+							},
+						},
+						{
+							Name:      "b",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+							RenamedFrom: model.MaybeIdent{
+								Valid: true,
+								Ident: "old_b",
+							},
+						},
+						{
+							Name:      "c",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+							RenamedFrom: model.MaybeIdent{
+								Valid: true,
+								Ident: "old_c",
+							},
+						},
+						{
+							Name:      "d",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+						},
+					},
+					Options: []*model.TableOption{},
+				},
+			},
+		},
+		{
+			src: "CREATE OR REPLACE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS " +
+				"select `hoge`.`id` AS `id` from `hoge`;\n" +
+				"CREATE VIEW v2 (a, b) AS SELECT 1, '2' WITH LOCAL CHECK OPTION;",
+			want: model.Stmts{
+				&model.View{
+					Name:        "v",
+					OrReplace:   true,
+					Algorithm:   "UNDEFINED",
+					Definer:     "`root`@`%`",
+					SQLSecurity: "DEFINER",
+					Definition:  "select `hoge`.`id` AS `id` from `hoge`",
+				},
+				&model.View{
+					Name:        "v2",
+					Columns:     []model.Ident{"a", "b"},
+					Definition:  "SELECT 1, '2'",
+					CheckOption: "LOCAL",
+				},
+			},
+		},
+		{
+			src: "CREATE TABLE `hoge` (\n" +
+				"`a` int(11) NOT NULL,\n" +
+				"`b` int(11) GENERATED ALWAYS AS ((`a` + (1))) VIRTUAL,\n" +
+				"`c` varchar(10) AS (concat(')', `a`)) STORED NOT NULL COMMENT 'c'\n" +
+				")",
+			want: model.Stmts{
+				&model.Table{
+					Name: "hoge",
+					Columns: []*model.TableColumn{
+						{
+							Name:      "a",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+						},
+						{
+							Name:   "b",
+							Type:   model.ColumnTypeInt,
+							Length: model.NewLength("11"),
+							Generated: model.MaybeString{
+								Valid: true,
+								Value: "(`a` + (1))",
+							},
+							GeneratedStorage: model.GeneratedStorageVirtual,
+						},
+						{
+							Name:      "c",
+							Type:      model.ColumnTypeVarChar,
+							Length:    model.NewLength("10"),
+							NullState: model.NullStateNotNull,
+							Comment: model.MaybeString{
+								Valid: true,
+								Value: "c",
+							},
+							Generated: model.MaybeString{
+								Valid: true,
+								Value: "concat(')', `a`)",
+							},
+							GeneratedStorage: model.GeneratedStorageStored,
+						},
+					},
+					Options: []*model.TableOption{},
+				},
+			},
+		},
+		{
+			src: "CREATE TABLE `hoge` (\n" +
+				"`a` int NOT NULL CHECK (`a` > 0),\n" +
+				"`b` int NOT NULL CONSTRAINT `b_positive` CHECK (`b` > 0) NOT ENFORCED,\n" +
+				"CONSTRAINT `a_b` CHECK ((`a` < `b`)) /*!80016 NOT ENFORCED */,\n" +
+				"CHECK (`a` <> 10)\n" +
+				")",
+			want: model.Stmts{
+				&model.Table{
+					Name: "hoge",
+					Columns: []*model.TableColumn{
+						{
+							Name:      "a",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+						},
+						{
+							Name:      "b",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+						},
+					},
+					Checks: []*model.Check{
+						{
+							Name: model.MaybeIdent{Valid: true, Ident: "hoge_chk_1"},
+							Expr: "`a` > 0",
+						},
+						{
+							Name:        model.MaybeIdent{Valid: true, Ident: "b_positive"},
+							Expr:        "`b` > 0",
+							NotEnforced: true,
+						},
+						{
+							Name:        model.MaybeIdent{Valid: true, Ident: "a_b"},
+							Expr:        "(`a` < `b`)",
+							NotEnforced: true,
+						},
+						{
+							Name: model.MaybeIdent{Valid: true, Ident: "hoge_chk_2"},
+							Expr: "`a` <> 10",
+						},
+					},
+					Options: []*model.TableOption{},
+				},
+			},
+		},
+		{
+			src: "USE `db`;\n" +
+				"CREATE TABLE `hoge` (\n" +
+				"`id` int NOT NULL,\n" +
+				"PRIMARY KEY (`id`),\n" +
+				"FOREIGN KEY (`id`) REFERENCES `other`.`fuga` (`id`)\n" +
+				")",
+			want: model.Stmts{
+				&model.Table{
+					Schema: "db",
+					Name:   "hoge",
+					Columns: []*model.TableColumn{
+						{
+							Name:      "id",
+							Type:      model.ColumnTypeInt,
+							Length:    model.NewLength("11"),
+							NullState: model.NullStateNotNull,
+						},
+					},
+					Indexes: []*model.Index{
+						{
+							Table: "table#db.hoge",
+							Kind:  model.IndexKindPrimaryKey,
+							Columns: []*model.IndexColumn{
+								{Name: "id"},
+							},
+						},
+						{
+							Table: "table#db.hoge",
+							Kind:  model.IndexKindForeignKey,
+							Columns: []*model.IndexColumn{
+								{Name: "id"},
+							},
+							Reference: &model.Reference{
+								TableSchema: "other",
+								TableName:   "fuga",
+								Columns: []*model.IndexColumn{
+									{Name: "id"},
+								},
+							},
+						},
+					},
+					Options: []*model.TableOption{},
+				},
+			},
+		},
+		{
+			src: "DELIMITER ;;\n" +
+				"CREATE PROCEDURE `p`() IF 1 THEN SELECT 1; END IF;;\n" +
+				"DELIMITER ;\n" +
+				"CREATE PROCEDURE `q`() lbl: LOOP LEAVE lbl; END LOOP lbl;",
+			want: model.Stmts{
+				&model.Routine{
+					Kind: model.RoutineKindProcedure,
+					Name: "p",
+					Body: "IF 1 THEN SELECT 1; END IF",
+				},
+				&model.Routine{
+					Kind: model.RoutineKindProcedure,
+					Name: "q",
+					Body: "lbl: LOOP LEAVE lbl; END LOOP lbl",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		p := schemalex.New()
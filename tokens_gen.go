@@ -50,6 +50,7 @@ const (
 	RPAREN        // )
 	COMMA         // ,
 	SEMICOLON     // ;
+	DELIMITER     // the delimiter changed by the DELIMITER command, such as ;; and //
 	DOT           // .
 	SLASH         // /
 	ASTERISK      // *
@@ -58,8 +59,10 @@ const (
 	SINGLE_QUOTE  // '
 	DOUBLE_QUOTE  // "
 	EQUAL         // =
+	ATMARK        // @
 	COMMENT_IDENT // // /*   */, --, #
 	ACTION
+	AS
 	ASC
 	AUTO_INCREMENT
 	AVG_ROW_LENGTH
@@ -140,6 +143,7 @@ const (
 	NULL
 	NUMERIC
 	ON
+	OR
 	PACK_KEYS
 	PARSER
 	PARTIAL
@@ -150,6 +154,7 @@ const (
 	REAL
 	REDUNDANT
 	REFERENCES
+	REPLACE
 	RESTRICT
 	ROW_FORMAT
 	SET
@@ -186,6 +191,7 @@ const (
 
 var keywordIdentMap = map[string]TokenType{
 	"ACTION":             ACTION,
+	"AS":                 AS,
 	"ASC":                ASC,
 	"AUTO_INCREMENT":     AUTO_INCREMENT,
 	"AVG_ROW_LENGTH":     AVG_ROW_LENGTH,
@@ -266,6 +272,7 @@ var keywordIdentMap = map[string]TokenType{
 	"NULL":               NULL,
 	"NUMERIC":            NUMERIC,
 	"ON":                 ON,
+	"OR":                 OR,
 	"PACK_KEYS":          PACK_KEYS,
 	"PARSER":             PARSER,
 	"PARTIAL":            PARTIAL,
@@ -276,6 +283,7 @@ var keywordIdentMap = map[string]TokenType{
 	"REAL":               REAL,
 	"REDUNDANT":          REDUNDANT,
 	"REFERENCES":         REFERENCES,
+	"REPLACE":            REPLACE,
 	"RESTRICT":           RESTRICT,
 	"ROW_FORMAT":         ROW_FORMAT,
 	"SET":                SET,
@@ -336,6 +344,8 @@ func (t TokenType) String() string {
 		return "COMMA"
 	case SEMICOLON:
 		return "SEMICOLON"
+	case DELIMITER:
+		return "DELIMITER"
 	case DOT:
 		return "DOT"
 	case SLASH:
@@ -352,10 +362,14 @@ func (t TokenType) String() string {
 		return "DOUBLE_QUOTE"
 	case EQUAL:
 		return "EQUAL"
+	case ATMARK:
+		return "ATMARK"
 	case COMMENT_IDENT:
 		return "COMMENT_IDENT"
 	case ACTION:
 		return "ACTION"
+	case AS:
+		return "AS"
 	case ASC:
 		return "ASC"
 	case AUTO_INCREMENT:
@@ -516,6 +530,8 @@ func (t TokenType) String() string {
 		return "NUMERIC"
 	case ON:
 		return "ON"
+	case OR:
+		return "OR"
 	case PACK_KEYS:
 		return "PACK_KEYS"
 	case PARSER:
@@ -536,6 +552,8 @@ func (t TokenType) String() string {
 		return "REDUNDANT"
 	case REFERENCES:
 		return "REFERENCES"
+	case REPLACE:
+		return "REPLACE"
 	case RESTRICT:
 		return "RESTRICT"
 	case ROW_FORMAT: